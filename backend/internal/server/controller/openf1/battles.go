@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1BattleRoutes registers routes for sustained battles and DRS trains.
+func RegisterOpenF1BattleRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/battles/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parseBattleOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewBattleService(service.NewOpenF1Service(logger))
+			battles, err := svc.GetSessionBattles(c.Request.Context(), sessionKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, battles)
+		})
+	}
+}
+
+func parseBattleOptions(c *gin.Context) (service.BattleOptions, error) {
+	opts := service.DefaultBattleOptions()
+
+	if raw := c.Query("max_gap"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return opts, fmt.Errorf("invalid max_gap")
+		}
+		opts.MaxGap = v
+	}
+	if raw := c.Query("min_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid min_laps")
+		}
+		opts.MinLaps = v
+	}
+	return opts, nil
+}
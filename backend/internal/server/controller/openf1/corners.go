@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1CornerRoutes registers routes for braking point and corner comparisons.
+func RegisterOpenF1CornerRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		// drivers=1,16 必填；laps=12,0 可選，0 表示該車手最快圈
+		group.GET("/corners/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			drivers, err := parseIntList(c.Query("drivers"))
+			if err != nil || len(drivers) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid drivers"})
+				return
+			}
+			var laps []int
+			if raw := c.Query("laps"); raw != "" {
+				if laps, err = parseIntList(raw); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid laps"})
+					return
+				}
+			}
+
+			svc := service.NewCornerAnalysisService(service.NewOpenF1Service(logger))
+			corners, err := svc.CompareCorners(c.Request.Context(), sessionKey, drivers, laps)
+			if errors.Is(err, service.ErrInvalidCornerRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, corners)
+		})
+	}
+}
+
+func parseIntList(raw string) ([]int, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, ",")
+	list := make([]int, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
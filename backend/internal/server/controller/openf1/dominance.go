@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1DominanceRoutes registers routes for the mini-sector track dominance map.
+func RegisterOpenF1DominanceRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		// mini_sectors、by=team、drivers=1,16、exclude_neutralised=true 可選；format=svg 直接回傳圖檔
+		group.GET("/dominance/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts := service.DominanceOptions{MiniSectors: service.DefaultMiniSectors}
+			if raw := c.Query("mini_sectors"); raw != "" {
+				if opts.MiniSectors, err = strconv.Atoi(raw); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mini_sectors"})
+					return
+				}
+			}
+			switch c.DefaultQuery("by", "driver") {
+			case "driver":
+			case "team":
+				opts.ByTeam = true
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid by"})
+				return
+			}
+			if opts.Drivers, err = parseIntList(c.Query("drivers")); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid drivers"})
+				return
+			}
+			if opts.LapFilter, err = parseLapFilter(c); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewDominanceService(service.NewOpenF1Service(logger))
+			dominance, err := svc.GetDominanceMap(c.Request.Context(), sessionKey, opts)
+			if errors.Is(err, service.ErrInvalidDominanceOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			if c.Query("format") == "svg" {
+				width := 800
+				if raw := c.Query("width"); raw != "" {
+					if width, err = strconv.Atoi(raw); err != nil || width < 100 {
+						c.JSON(http.StatusBadRequest, gin.H{"error": "invalid width"})
+						return
+					}
+				}
+				c.Data(http.StatusOK, "image/svg+xml", service.RenderDominanceSVG(dominance, width))
+				return
+			}
+
+			c.JSON(http.StatusOK, dominance)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
 	"net/http"
 	"strconv"
 )
@@ -59,5 +60,22 @@ func RegisterOpenF1DriverRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 			}
 			c.Data(http.StatusOK, "application/json", data)
 		})
+
+		group.GET("/drivers/season/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			registryService := service.NewDriverRegistryService(service.NewOpenF1Service(logger))
+			registry, err := registryService.GetSeasonRegistry(c.Request.Context(), year)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, registry.Drivers())
+		})
 	}
 }
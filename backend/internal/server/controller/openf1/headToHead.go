@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1HeadToHeadRoutes registers routes for teammate head-to-head comparisons.
+func RegisterOpenF1HeadToHeadRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/head_to_head/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			svc := service.NewHeadToHeadService(service.NewOpenF1Service(logger))
+			h2h, err := svc.GetSeasonHeadToHead(c.Request.Context(), year)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, h2h)
+		})
+	}
+}
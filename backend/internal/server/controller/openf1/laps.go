@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
 	"net/http"
+	"sort"
 	"strconv"
 )
 
@@ -12,10 +14,48 @@ import (
 func RegisterOpenF1LapsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 	group := rg.Group("/openf1")
 	{
-		group.GET("/laps/:sessions_key/:driver_number", func(c *gin.Context) {
-			datasource := datasource.NewOpenF1Datasource(logger)
-			defer datasource.Close()
+		// 整個 session 所有車手的圈，SC / VSC / 紅旗圈帶有 neutralised 欄位；exclude_neutralised=true 可直接排除
+		group.GET("/laps/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
 
+			filter, err := parseLapFilter(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewLapService(service.NewOpenF1Service(logger))
+			lapHistory, _, err := svc.GetMarkedLapHistory(c.Request.Context(), sessionKey)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			laps := []service.LapRecord{}
+			for _, history := range lapHistory {
+				for _, lap := range history {
+					if filter.ExcludeNeutralised && lap.Neutralised != "" {
+						continue
+					}
+					laps = append(laps, lap)
+				}
+			}
+			sort.Slice(laps, func(i, j int) bool {
+				if laps[i].DriverNumber != laps[j].DriverNumber {
+					return laps[i].DriverNumber < laps[j].DriverNumber
+				}
+				return laps[i].LapNumber < laps[j].LapNumber
+			})
+
+			c.JSON(http.StatusOK, laps)
+		})
+
+		// OpenF1 原始圈資料，加上 deleted / deleted_reason / neutralised
+		group.GET("/laps/:sessions_key/:driver_number", func(c *gin.Context) {
 			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
@@ -28,7 +68,8 @@ func RegisterOpenF1LapsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 				return
 			}
 
-			data, err := datasource.GetLapsByDriver(c.Request.Context(), sessionKey, driverNumber)
+			svc := service.NewLapService(service.NewOpenF1Service(logger))
+			data, err := svc.GetDriverLaps(c.Request.Context(), sessionKey, driverNumber)
 			if err != nil {
 				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 				return
@@ -38,3 +79,16 @@ func RegisterOpenF1LapsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 		})
 	}
 }
+
+// parseLapFilter 解析各分析路由共用的 exclude_neutralised 參數
+func parseLapFilter(c *gin.Context) (service.LapFilter, error) {
+	var filter service.LapFilter
+	if raw := c.Query("exclude_neutralised"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exclude_neutralised")
+		}
+		filter.ExcludeNeutralised = v
+	}
+	return filter, nil
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1LongRunRoutes registers routes for practice long-run analysis.
+func RegisterOpenF1LongRunRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/long_runs/sessions/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parseLongRunOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewLongRunService(service.NewOpenF1Service(logger))
+			runs, err := svc.GetSessionLongRuns(c.Request.Context(), sessionKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, runs)
+		})
+
+		group.GET("/long_runs/meeting/:meeting_key", func(c *gin.Context) {
+			meetingKey, err := strconv.Atoi(c.Param("meeting_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid meeting_key"})
+				return
+			}
+
+			opts, err := parseLongRunOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewLongRunService(service.NewOpenF1Service(logger))
+			runs, err := svc.GetMeetingLongRuns(c.Request.Context(), meetingKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, runs)
+		})
+	}
+}
+
+// parseLongRunOptions 解析 min_laps、representative_threshold、max_slow_laps、min_representative_share、fuel_correction、exclude_neutralised，未帶入時使用預設值
+func parseLongRunOptions(c *gin.Context) (service.LongRunOptions, error) {
+	opts := service.DefaultLongRunOptions()
+
+	if raw := c.Query("min_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 2 {
+			return opts, fmt.Errorf("invalid min_laps")
+		}
+		opts.MinLaps = v
+	}
+	if raw := c.Query("representative_threshold"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid representative_threshold")
+		}
+		opts.RepresentativeThreshold = v
+	}
+	if raw := c.Query("max_slow_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return opts, fmt.Errorf("invalid max_slow_laps")
+		}
+		opts.MaxSlowLaps = v
+	}
+	if raw := c.Query("min_representative_share"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 1 {
+			return opts, fmt.Errorf("invalid min_representative_share")
+		}
+		opts.MinRepresentativeShare = v
+	}
+	if raw := c.Query("fuel_correction"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return opts, fmt.Errorf("invalid fuel_correction")
+		}
+		opts.FuelCorrection = v
+	}
+	filter, err := parseLapFilter(c)
+	if err != nil {
+		return opts, err
+	}
+	opts.LapFilter = filter
+	return opts, nil
+}
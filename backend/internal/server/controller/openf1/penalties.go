@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1PenaltyRoutes registers the stewards' incident and penalty tracker routes.
+func RegisterOpenF1PenaltyRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1/penalties")
+	{
+		// 單一 session 的事件與裁決
+		group.GET("/sessions/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parsePenaltyTrackerOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewPenaltyTrackerService(service.NewOpenF1Service(logger))
+			incidents, err := svc.GetSessionIncidents(c.Request.Context(), sessionKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, incidents)
+		})
+
+		// 賽季累計：每位車手的處罰、申誡與超級駕照罰分
+		group.GET("/season/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			opts, err := parsePenaltyTrackerOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewPenaltyTrackerService(service.NewOpenF1Service(logger))
+			penalties, err := svc.GetSeasonPenalties(c.Request.Context(), year, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, penalties)
+		})
+
+		// 單一車手的賽季紀錄與相關事件
+		group.GET("/season/:year/drivers/:driver_number", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+			driverNumber, err := strconv.Atoi(c.Param("driver_number"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver_number"})
+				return
+			}
+
+			opts, err := parsePenaltyTrackerOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewPenaltyTrackerService(service.NewOpenF1Service(logger))
+			penalties, err := svc.GetSeasonPenalties(c.Request.Context(), year, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			// 沒有任何事件的車手回傳乾淨紀錄
+			record := service.DriverPenaltyRecord{
+				DriverNumber:        driverNumber,
+				PointsToBan:         opts.BanPoints,
+				ReprimandsToPenalty: opts.ReprimandLimit,
+			}
+			if idx := slices.IndexFunc(penalties.Drivers, func(d service.DriverPenaltyRecord) bool {
+				return d.DriverNumber == driverNumber
+			}); idx >= 0 {
+				record = penalties.Drivers[idx]
+			}
+			incidents := []service.Incident{}
+			for _, inc := range penalties.Incidents {
+				if slices.Contains(inc.Drivers, driverNumber) ||
+					(inc.PenalisedDriver != nil && *inc.PenalisedDriver == driverNumber) {
+					incidents = append(incidents, inc)
+				}
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"year":      year,
+				"driver":    record,
+				"incidents": incidents,
+			})
+		})
+	}
+}
+
+// parsePenaltyTrackerOptions 解析 points=CAUSING A COLLISION:3,IMPEDING:1、ban_points、reprimand_limit；
+// 自訂罰分優先於預設對照表
+func parsePenaltyTrackerOptions(c *gin.Context) (service.PenaltyTrackerOptions, error) {
+	opts := service.DefaultPenaltyTrackerOptions()
+
+	if raw := c.Query("points"); raw != "" {
+		var rules []service.PenaltyPointsRule
+		for _, part := range strings.Split(raw, ",") {
+			offence, value, ok := strings.Cut(part, ":")
+			points, err := strconv.Atoi(strings.TrimSpace(value))
+			if !ok || err != nil || points < 0 || strings.TrimSpace(offence) == "" {
+				return opts, fmt.Errorf("invalid points")
+			}
+			rules = append(rules, service.PenaltyPointsRule{Offence: strings.ToUpper(strings.TrimSpace(offence)), Points: points})
+		}
+		opts.PointsRules = append(rules, opts.PointsRules...)
+	}
+	if raw := c.Query("ban_points"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid ban_points")
+		}
+		opts.BanPoints = v
+	}
+	if raw := c.Query("reprimand_limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid reprimand_limit")
+		}
+		opts.ReprimandLimit = v
+	}
+	return opts, nil
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1PitStrategyRoutes registers routes for undercut/overcut detection and team aggregates.
+func RegisterOpenF1PitStrategyRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1/pit_strategy")
+	{
+		// 單場正賽的 undercut / overcut
+		group.GET("/sessions/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parsePitStrategyOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewPitStrategyService(service.NewOpenF1Service(logger))
+			strategy, err := svc.GetSessionPitStrategy(c.Request.Context(), sessionKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, strategy)
+		})
+
+		// 賽季累計：各車隊的成功率與時間得失
+		group.GET("/season/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			opts, err := parsePitStrategyOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewPitStrategyService(service.NewOpenF1Service(logger))
+			strategy, err := svc.GetSeasonPitStrategy(c.Request.Context(), year, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, strategy)
+		})
+	}
+}
+
+func parsePitStrategyOptions(c *gin.Context) (service.PitStrategyOptions, error) {
+	opts := service.DefaultPitStrategyOptions()
+
+	if raw := c.Query("max_gap"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return opts, fmt.Errorf("invalid max_gap")
+		}
+		opts.MaxGap = v
+	}
+	if raw := c.Query("max_laps_apart"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid max_laps_apart")
+		}
+		opts.MaxLapsApart = v
+	}
+	return opts, nil
+}
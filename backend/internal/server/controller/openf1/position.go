@@ -23,13 +23,13 @@ func RegisterOpenF1PositionRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 			}
 
 			svc := service.NewPositionService(service.NewOpenF1Service(logger))
-			positionData, err := svc.GetSessionLapRankings(c.Request.Context(), sessionKey)
+			lapChart, err := svc.GetSessionLapChart(c.Request.Context(), sessionKey)
 			if err != nil {
 				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 				return
 			}
 
-			c.JSON(http.StatusOK, positionData)
+			c.JSON(http.StatusOK, lapChart)
 		})
 	}
 }
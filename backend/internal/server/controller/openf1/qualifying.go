@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1QualifyingRoutes registers routes for the Q1/Q2/Q3 knockout analysis.
+func RegisterOpenF1QualifyingRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/qualifying/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			svc := service.NewQualifyingService(service.NewOpenF1Service(logger))
+			analysis, err := svc.GetSessionQualifying(c.Request.Context(), sessionKey)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, analysis)
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // RegisterOpenF1MeetingRoutes registers routes related to OpenF1 meetings.
@@ -30,5 +32,28 @@ func RegisterOpenF1RaceControlRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 
 			c.Data(http.StatusOK, "application/json", data)
 		})
+
+		// 解析後的事件，type=penalty,track_limits 可篩選
+		group.GET("/race_control/:sessions_key/events", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			var types []string
+			if raw := c.Query("type"); raw != "" {
+				types = strings.Split(raw, ",")
+			}
+
+			svc := service.NewRaceControlService(service.NewOpenF1Service(logger))
+			events, err := svc.GetSessionEvents(c.Request.Context(), sessionKey, types...)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, events)
+		})
 	}
 }
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1RacePaceRoutes registers routes for fuel-corrected race pace analysis.
+func RegisterOpenF1RacePaceRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/race_pace/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parseRacePaceOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewRacePaceService(service.NewOpenF1Service(logger))
+			pace, err := svc.GetSessionRacePace(c.Request.Context(), sessionKey, opts)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, pace)
+		})
+	}
+}
+
+// parseRacePaceOptions 解析 fuel_correction、outlier_threshold、min_stint_laps，未帶入時使用預設值
+func parseRacePaceOptions(c *gin.Context) (service.RacePaceOptions, error) {
+	opts := service.DefaultRacePaceOptions()
+
+	if raw := c.Query("fuel_correction"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return opts, fmt.Errorf("invalid fuel_correction")
+		}
+		opts.FuelCorrection = v
+	}
+	if raw := c.Query("outlier_threshold"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || (v != 0 && v < 1) {
+			return opts, fmt.Errorf("invalid outlier_threshold")
+		}
+		opts.OutlierThreshold = v
+	}
+	if raw := c.Query("min_stint_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 2 {
+			return opts, fmt.Errorf("invalid min_stint_laps")
+		}
+		opts.MinStintLaps = v
+	}
+	return opts, nil
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1RaceSummaryRoutes registers routes for positions gained at the start, on lap 1 and over the race.
+func RegisterOpenF1RaceSummaryRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1/race_summary")
+	{
+		// 單場正賽的名次得失
+		group.GET("/sessions/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			svc := service.NewRaceSummaryService(service.NewOpenF1Service(logger))
+			summary, err := svc.GetSessionRaceSummary(c.Request.Context(), sessionKey)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, summary)
+		})
+
+		// 賽季累計：最會起跑與正賽追分最多的車手
+		group.GET("/season/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			svc := service.NewRaceSummaryService(service.NewOpenF1Service(logger))
+			summary, err := svc.GetSeasonRaceSummary(c.Request.Context(), year)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, summary)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1SpeedTrapRoutes registers routes for speed trap leaderboards and team top-speed deficits.
+func RegisterOpenF1SpeedTrapRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1")
+	{
+		group.GET("/speed_traps/sessions/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			filter, err := parseLapFilter(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewSpeedTrapService(service.NewOpenF1Service(logger))
+			traps, err := svc.GetSessionSpeedTraps(c.Request.Context(), sessionKey, filter)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, traps)
+		})
+
+		// session 預設為 Race，可指定 Qualifying 等比較排位設定
+		group.GET("/speed_traps/season/:year", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+			sessionName := c.DefaultQuery("session", "Race")
+
+			svc := service.NewSpeedTrapService(service.NewOpenF1Service(logger))
+			deficit, err := svc.GetSeasonSpeedDeficit(c.Request.Context(), year, sessionName)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, deficit)
+		})
+	}
+}
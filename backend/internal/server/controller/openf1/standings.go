@@ -1,15 +1,19 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"lovdlwlrma/backend/internal/server/service/openf1/service"
+	"lovdlwlrma/backend/internal/server/service/race"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // RegisterOpenF1MeetingRoutes registers routes related to OpenF1 meetings.
-func RegisterOpenF1StandingsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+func RegisterOpenF1StandingsRoutes(rg *gin.RouterGroup, logger *zap.Logger, raceService *race.Service) {
 	group := rg.Group("/openf1")
 	{
 		group.GET("/standings/:year", func(c *gin.Context) {
@@ -19,8 +23,23 @@ func RegisterOpenF1StandingsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 				return
 			}
 
+			opts, err := parseWhatIfOptions(c, year)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
 			standingsService := service.NewStandingsService(service.NewOpenF1Service(logger), logger)
-			standingsData, err := standingsService.GetStandingsHistory(c.Request.Context(), year)
+			var standingsData *service.StandingsHistory
+			if opts != nil {
+				standingsData, err = standingsService.GetWhatIfStandings(c.Request.Context(), year, opts)
+			} else {
+				standingsData, err = standingsService.GetStandingsHistory(c.Request.Context(), year)
+			}
+			if errors.Is(err, service.ErrInvalidWhatIf) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			if err != nil {
 				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 				return
@@ -28,5 +47,130 @@ func RegisterOpenF1StandingsRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 
 			c.JSON(http.StatusOK, standingsData)
 		})
+
+		group.GET("/points_systems", func(c *gin.Context) {
+			c.JSON(http.StatusOK, service.ListPointsSystems())
+		})
+
+		group.GET("/standings/:year/scenarios", func(c *gin.Context) {
+			year, err := strconv.Atoi(c.Param("year"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+				return
+			}
+
+			standingsService := service.NewStandingsService(service.NewOpenF1Service(logger), logger)
+			scenarioService := service.NewScenarioService(standingsService, raceService)
+			scenarios, err := scenarioService.GetChampionshipScenarios(c.Request.Context(), year)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, scenarios)
+		})
+	}
+}
+
+// parseWhatIfOptions 解析 what-if 查詢參數，未帶任何參數時回傳 nil：
+//
+//	points_system=2003-2009                    使用內建的積分制度
+//	race_points=10,8,6&sprint_points=3,2,1     自訂積分表 (可加 fastest_lap_point=1)
+//	exclude_rounds=3,5                         排除第 3、5 場
+//	swap=1:4 或 swap=7:1:4                      交換車手 1 與 4 在所有場次 / 第 7 場的成績
+func parseWhatIfOptions(c *gin.Context, year int) (*service.WhatIfOptions, error) {
+	opts := &service.WhatIfOptions{}
+	used := false
+
+	if name := c.Query("points_system"); name != "" {
+		ps, err := service.GetPointsSystem(name, year)
+		if err != nil {
+			return nil, err
+		}
+		opts.PointsSystem = ps
+		used = true
+	}
+
+	// sprint_points 與 fastest_lap_point 只能搭配 race_points 使用
+	if c.Query("race_points") == "" && (c.Query("sprint_points") != "" || c.Query("fastest_lap_point") != "") {
+		return nil, fmt.Errorf("sprint_points and fastest_lap_point require race_points")
+	}
+
+	if raw := c.Query("race_points"); raw != "" {
+		if opts.PointsSystem != nil {
+			return nil, fmt.Errorf("points_system and race_points are mutually exclusive")
+		}
+		racePoints, err := parseFloatList(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid race_points: %w", err)
+		}
+		var sprintPoints []float64
+		if rawSprint := c.Query("sprint_points"); rawSprint != "" {
+			if sprintPoints, err = parseFloatList(rawSprint); err != nil {
+				return nil, fmt.Errorf("invalid sprint_points: %w", err)
+			}
+		}
+		fastestLap := 0.0
+		if rawFL := c.Query("fastest_lap_point"); rawFL != "" {
+			if fastestLap, err = strconv.ParseFloat(rawFL, 64); err != nil {
+				return nil, fmt.Errorf("invalid fastest_lap_point: %w", err)
+			}
+		}
+		ps, err := service.NewCustomPointsSystem(racePoints, sprintPoints, fastestLap)
+		if err != nil {
+			return nil, err
+		}
+		opts.PointsSystem = ps
+		used = true
+	}
+
+	if raw := c.Query("exclude_rounds"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			round, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude_rounds: %q", part)
+			}
+			opts.ExcludeRounds = append(opts.ExcludeRounds, round)
+		}
+		used = true
+	}
+
+	for _, raw := range c.QueryArray("swap") {
+		parts := strings.Split(raw, ":")
+		nums := make([]int, len(parts))
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid swap: %q", raw)
+			}
+			nums[i] = n
+		}
+		switch len(nums) {
+		case 2:
+			opts.Swaps = append(opts.Swaps, service.ResultSwap{DriverA: nums[0], DriverB: nums[1]})
+		case 3:
+			opts.Swaps = append(opts.Swaps, service.ResultSwap{Round: nums[0], DriverA: nums[1], DriverB: nums[2]})
+		default:
+			return nil, fmt.Errorf("invalid swap: %q", raw)
+		}
+		used = true
+	}
+
+	if !used {
+		return nil, nil
+	}
+	return opts, nil
+}
+
+func parseFloatList(raw string) ([]float64, error) {
+	parts := strings.Split(raw, ",")
+	list := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
 	}
+	return list, nil
 }
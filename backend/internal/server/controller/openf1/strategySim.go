@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterOpenF1StrategySimRoutes registers routes for the race strategy simulator.
+func RegisterOpenF1StrategySimRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
+	group := rg.Group("/openf1/strategy_sim")
+	{
+		// 以該場正賽的歷史資料為預設輸入，評估所有 1 ~ 3 停策略
+		group.GET("/:sessions_key", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+
+			opts, err := parseStrategySimOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewStrategySimService(service.NewOpenF1Service(logger))
+			sim, err := svc.GetStrategySimulation(c.Request.Context(), sessionKey, opts)
+			if errors.Is(err, service.ErrInvalidStrategySimOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, sim)
+		})
+	}
+}
+
+func parseStrategySimOptions(c *gin.Context) (service.StrategySimOptions, error) {
+	opts := service.DefaultStrategySimOptions()
+
+	if raw := c.Query("driver_number"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid driver_number")
+		}
+		opts.DriverNumber = v
+	}
+	if raw := c.Query("base_pace"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return opts, fmt.Errorf("invalid base_pace")
+		}
+		opts.BasePace = v
+	}
+	if raw := c.Query("pit_loss"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return opts, fmt.Errorf("invalid pit_loss")
+		}
+		opts.PitLoss = v
+	}
+	if raw := c.Query("total_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 2 || v > service.MaxSimTotalLaps {
+			return opts, fmt.Errorf("invalid total_laps")
+		}
+		opts.TotalLaps = v
+	}
+	if raw := c.Query("fuel_correction"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return opts, fmt.Errorf("invalid fuel_correction")
+		}
+		opts.FuelCorrection = v
+	}
+	// compounds=SOFT:-0.5:0.1,HARD:0.5:0.035 (配方:偏移:衰退)
+	if raw := c.Query("compounds"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			fields := strings.Split(part, ":")
+			if len(fields) != 3 {
+				return opts, fmt.Errorf("invalid compounds")
+			}
+			offset, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return opts, fmt.Errorf("invalid compounds")
+			}
+			deg, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil || deg < 0 {
+				return opts, fmt.Errorf("invalid compounds")
+			}
+			compound := strings.ToUpper(strings.TrimSpace(fields[0]))
+			if compound != "SOFT" && compound != "MEDIUM" && compound != "HARD" {
+				return opts, fmt.Errorf("invalid compounds")
+			}
+			opts.Compounds = append(opts.Compounds, service.CompoundModel{
+				Compound:    compound,
+				Offset:      offset,
+				Degradation: deg,
+			})
+		}
+	}
+	if raw := c.Query("require_two_compounds"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("invalid require_two_compounds")
+		}
+		opts.RequireTwoCompounds = v
+	}
+	if raw := c.Query("min_stint_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid min_stint_laps")
+		}
+		opts.MinStintLaps = v
+	}
+	if raw := c.Query("max_stops"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > service.DefaultSimMaxStops {
+			return opts, fmt.Errorf("invalid max_stops")
+		}
+		opts.MaxStops = v
+	}
+	if raw := c.Query("safety_car_probability"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 1 {
+			return opts, fmt.Errorf("invalid safety_car_probability")
+		}
+		opts.SafetyCarProbability = &v
+	}
+	if raw := c.Query("safety_car_laps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return opts, fmt.Errorf("invalid safety_car_laps")
+		}
+		opts.SafetyCarLaps = v
+	}
+	if raw := c.Query("simulations"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 || v > 100000 {
+			return opts, fmt.Errorf("invalid simulations")
+		}
+		opts.Simulations = v
+	}
+	if raw := c.Query("seed"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid seed")
+		}
+		opts.Seed = v
+	}
+	if raw := c.Query("history_years"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 || v > service.MaxSimHistoryYears {
+			return opts, fmt.Errorf("invalid history_years")
+		}
+		opts.HistoryYears = v
+	}
+	// 比賽圈數未指定時由服務在取得歷史圈數後檢查
+	if opts.TotalLaps > 0 && opts.MinStintLaps*(opts.MaxStops+1) > opts.TotalLaps {
+		return opts, fmt.Errorf("invalid min_stint_laps: %d stops need at least %d laps", opts.MaxStops, opts.MinStintLaps*(opts.MaxStops+1))
+	}
+	return opts, nil
+}
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"lovdlwlrma/backend/internal/server/service/openf1/service"
 
@@ -34,7 +37,29 @@ func RegisterOpenF1TelemetryRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 				return
 			}
 
+			opts, hasOptions, err := parseTelemetryOptions(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
 			svc := service.NewTelemetryService(service.NewOpenF1Service(logger))
+
+			// 帶入任一選項時改回傳欄位式資料，否則維持原始樣本格式
+			if hasOptions {
+				telemetry, err := svc.GetLapTelemetry(c.Request.Context(), sessionKey, driverNumber, lapNumber, opts)
+				if errors.Is(err, service.ErrInvalidTelemetryOptions) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusOK, telemetry)
+				return
+			}
+
 			telemetryData, err := svc.GetLapCarData(c.Request.Context(), sessionKey, driverNumber, lapNumber)
 			if err != nil {
 				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
@@ -43,5 +68,135 @@ func RegisterOpenF1TelemetryRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 
 			c.JSON(http.StatusOK, telemetryData)
 		})
+
+		// 整個 session 的遙測通道統計，drivers=1,16、exclude_neutralised=true 可選
+		group.GET("/telemetry/:sessions_key/stats", func(c *gin.Context) {
+			sessionKey, err := strconv.Atoi(c.Param("sessions_key"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sessions_key"})
+				return
+			}
+			drivers, err := parseIntList(c.Query("drivers"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid drivers"})
+				return
+			}
+			filter, err := parseLapFilter(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			svc := service.NewTelemetryStatsService(service.NewOpenF1Service(logger))
+			stats, err := svc.GetSessionTelemetryStats(c.Request.Context(), sessionKey, drivers, filter)
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, stats)
+		})
+
+		// 兩圈遙測比較，session_b 未指定時與 session_a 相同
+		group.GET("/telemetry/compare", func(c *gin.Context) {
+			reference, err := parseTelemetryLapRef(c, "a", 0)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			compare, err := parseTelemetryLapRef(c, "b", reference.SessionKey)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			step := service.DefaultCompareStep
+			if raw := c.Query("step"); raw != "" {
+				step, err = strconv.ParseFloat(raw, 64)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step"})
+					return
+				}
+			}
+
+			svc := service.NewTelemetryService(service.NewOpenF1Service(logger))
+			comparison, err := svc.CompareLaps(c.Request.Context(), reference, compare, step)
+			if errors.Is(err, service.ErrInvalidCompareStep) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, comparison)
+		})
+	}
+}
+
+// parseTelemetryLapRef 解析 session_<suffix>、driver_<suffix>、lap_<suffix>；defaultSession 非 0 時 session 可省略
+func parseTelemetryLapRef(c *gin.Context, suffix string, defaultSession int) (service.TelemetryLapRef, error) {
+	ref := service.TelemetryLapRef{SessionKey: defaultSession}
+
+	if raw := c.Query("session_" + suffix); raw != "" || defaultSession == 0 {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return ref, fmt.Errorf("invalid session_%s", suffix)
+		}
+		ref.SessionKey = v
+	}
+	v, err := strconv.Atoi(c.Query("driver_" + suffix))
+	if err != nil {
+		return ref, fmt.Errorf("invalid driver_%s", suffix)
+	}
+	ref.DriverNumber = v
+	v, err = strconv.Atoi(c.Query("lap_" + suffix))
+	if err != nil {
+		return ref, fmt.Errorf("invalid lap_%s", suffix)
+	}
+	ref.LapNumber = v
+	return ref, nil
+}
+
+// parseTelemetryOptions 解析 points、hz、channels (逗號分隔)、time=offset；回傳是否帶入任一選項
+func parseTelemetryOptions(c *gin.Context) (service.TelemetryOptions, bool, error) {
+	var opts service.TelemetryOptions
+	hasOptions := false
+
+	if raw := c.Query("points"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 3 {
+			return opts, false, fmt.Errorf("invalid points")
+		}
+		opts.Points = v
+		hasOptions = true
+	}
+	if raw := c.Query("hz"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return opts, false, fmt.Errorf("invalid hz")
+		}
+		opts.Hz = v
+		hasOptions = true
+	}
+	if raw := c.Query("channels"); raw != "" {
+		for _, ch := range strings.Split(raw, ",") {
+			if ch = strings.TrimSpace(ch); ch != "" {
+				opts.Channels = append(opts.Channels, ch)
+			}
+		}
+		hasOptions = true
+	}
+	switch c.Query("time") {
+	case "":
+	case "offset":
+		opts.TimeOffset = true
+		hasOptions = true
+	case "date":
+		hasOptions = true
+	default:
+		return opts, false, fmt.Errorf("invalid time")
 	}
+	return opts, hasOptions, nil
 }
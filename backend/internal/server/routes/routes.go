@@ -19,6 +19,9 @@ const (
 func RegisterRoutes(rg *gin.RouterGroup) {
 	controller.RegisterHealthRoutes(rg)
 
+	raceLogger := log.With(zap.String("service", "race"))
+	raceService := raceservice.NewService(raceAPI, raceLogger)
+
 	// OpenF1 API endpoints
 	f1logger := log.With(zap.String("service", "openf1"))
 	openf1controller.RegisterOpenF1SessionRoutes(rg, f1logger)
@@ -31,10 +34,23 @@ func RegisterRoutes(rg *gin.RouterGroup) {
 	openf1controller.RegisterOpenF1StintsRoutes(rg, f1logger)
 	openf1controller.RegisterOpenF1ResultRoutes(rg, f1logger)
 	openf1controller.RegisterOpenF1RaceControlRoutes(rg, f1logger)
-	openf1controller.RegisterOpenF1StandingsRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1StandingsRoutes(rg, f1logger, raceService)
+	openf1controller.RegisterOpenF1HeadToHeadRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1RacePaceRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1LongRunRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1SectorRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1SpeedTrapRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1CornerRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1DominanceRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1NeutralisationRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1PenaltyRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1TrackLimitsRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1RaceSummaryRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1QualifyingRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1BattleRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1PitStrategyRoutes(rg, f1logger)
+	openf1controller.RegisterOpenF1StrategySimRoutes(rg, f1logger)
 
 	// Race endpoints
-	raceLogger := log.With(zap.String("service", "race"))
-	raceService := raceservice.NewService(raceAPI, raceLogger)
 	racecontroller.RegisterRaceRoutes(rg, raceLogger, raceService)
 }
@@ -20,3 +20,16 @@ func (o *OpenF1Datasource) GetCarDataByLap(ctx context.Context, sessionKey int,
 
 	return o.fetchJSON(ctx, req)
 }
+
+func (o *OpenF1Datasource) GetCarDataAboveSpeed(ctx context.Context, sessionKey int, minSpeed int) ([]byte, error) {
+	req := &httpclient.FetchRequest{
+		URL:    fmt.Sprintf("https://api.openf1.org/v1/car_data?session_key=%d&speed%%3E=%d", sessionKey, minSpeed),
+		Method: "GET",
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		Timeout: 30,
+	}
+
+	return o.fetchJSON(ctx, req)
+}
@@ -1,8 +1,22 @@
 package datasource
 
 type Lap struct {
-	LapNumber int    `json:"lap_number"`
-	DateStart string `json:"date_start"`
+	LapNumber       int     `json:"lap_number"`
+	DateStart       string  `json:"date_start"`
+	DriverNumber    int     `json:"driver_number"`
+	SessionKey      int     `json:"session_key"`
+	MeetingKey      int     `json:"meeting_key"`
+	LapDuration     float64 `json:"lap_duration"`
+	IsPitOutLap     bool    `json:"is_pit_out_lap"`
+	DurationSector1 float64 `json:"duration_sector_1"`
+	DurationSector2 float64 `json:"duration_sector_2"`
+	DurationSector3 float64 `json:"duration_sector_3"`
+	SegmentsSector1 []int   `json:"segments_sector_1"`
+	SegmentsSector2 []int   `json:"segments_sector_2"`
+	SegmentsSector3 []int   `json:"segments_sector_3"`
+	I1Speed         int     `json:"i1_speed"`
+	I2Speed         int     `json:"i2_speed"`
+	STSpeed         int     `json:"st_speed"`
 }
 
 type CarData struct {
@@ -15,3 +29,11 @@ type CarData struct {
 	Brake        int    `json:"brake"`
 	DRS          int    `json:"drs"`
 }
+
+type Location struct {
+	Date         string `json:"date"`
+	DriverNumber int    `json:"driver_number"`
+	X            int    `json:"x"`
+	Y            int    `json:"y"`
+	Z            int    `json:"z"`
+}
@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
 	"go.uber.org/zap"
 	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
 )
@@ -21,6 +26,19 @@ func NewOpenF1Service(logger *zap.Logger) *BaseService {
 	}
 }
 
+// 所有 service 與同時進行的請求共用同一個限速器；每個時段只會交給一個等待者
+var rateLimiter = time.NewTicker(350 * time.Millisecond) // 每秒最多 3 次
+
+// throttle 等待下一個對 OpenF1 發出請求的時段；請求取消時立即放棄排隊並回傳 ctx 的錯誤
+func (b *BaseService) throttle(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-rateLimiter.C:
+		return nil
+	}
+}
+
 func (b *BaseService) Close() {
 	if b.DS != nil {
 		b.DS.Close()
@@ -30,3 +48,42 @@ func (b *BaseService) Close() {
 func (b *BaseService) FetchJSON(ctx context.Context, fetchFunc func(ctx context.Context) ([]byte, error)) ([]byte, error) {
 	return fetchFunc(ctx)
 }
+
+// fetchRecords 抓取 OpenF1 JSON 陣列並解析成指定型別
+func fetchRecords[T any](ctx context.Context, b *BaseService, fetchFunc func(ctx context.Context) ([]byte, error)) ([]T, error) {
+	data, err := b.FetchJSON(ctx, fetchFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []T
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getPastSessions 取得指定年份已開始的 session (可依 session_name 篩選)，依開始時間排序
+func (b *BaseService) getPastSessions(ctx context.Context, year int, names ...string) ([]Session, error) {
+	all, err := fetchRecords[Session](ctx, b, func(ctx context.Context) ([]byte, error) {
+		return b.DS.GetYearSession(ctx, year)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var sessions []Session
+	for _, sess := range all {
+		if len(names) > 0 && !slices.Contains(names, sess.SessionName) {
+			continue
+		}
+		if start, err := time.Parse(time.RFC3339, sess.DateStart); err == nil && start.After(now) {
+			continue
+		}
+		sessions = append(sessions, sess)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].DateStart < sessions[j].DateStart })
+	return sessions, nil
+}
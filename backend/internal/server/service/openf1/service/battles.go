@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// 纏鬥與 DRS 車列的結束原因
+const (
+	BattleOutcomeOvertake      = "overtake"       // 前後順序互換
+	BattleOutcomePitDivergence = "pit_divergence" // 其中一車進站
+	BattleOutcomeGapOpened     = "gap_opened"     // 差距拉開超過門檻
+	BattleOutcomeRetirement    = "retirement"     // 其中一車退賽
+	BattleOutcomeRaceEnd       = "race_end"       // 持續到比賽結束
+)
+
+// 預設門檻：DRS 啟用距離 1 秒，至少連續 3 圈
+const (
+	DefaultBattleGap     = 1.0
+	DefaultBattleMinLaps = 3
+	drsTrainMinCars      = 3
+)
+
+// BattleOptions 纏鬥判定門檻
+type BattleOptions struct {
+	MaxGap  float64 `json:"max_gap"`  // 與前車差距 (秒) 不超過此值視為纏鬥
+	MinLaps int     `json:"min_laps"` // 至少持續的圈數
+}
+
+func DefaultBattleOptions() BattleOptions {
+	return BattleOptions{MaxGap: DefaultBattleGap, MinLaps: DefaultBattleMinLaps}
+}
+
+type BattleService struct {
+	*BaseService
+}
+
+func NewBattleService(base *BaseService) *BattleService {
+	return &BattleService{BaseService: base}
+}
+
+// =======================
+// 主入口: 纏鬥與 DRS 車列
+// =======================
+
+// GetSessionBattles 以每圈通過計時線的時間差推算與前車的差距，找出持續的一對一纏鬥與 3 車以上的 DRS 車列。
+// SC / VSC / 紅旗圈不計入也不中斷纏鬥。
+func (s *BattleService) GetSessionBattles(ctx context.Context, sessionKey int, opts BattleOptions) (*SessionBattles, error) {
+	lapHistory, periods, err := NewLapService(s.BaseService).GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := detectBattles(lapHistory, neutralisedLapNumbers(periods), opts)
+	result.SessionKey = sessionKey
+	return result, nil
+}
+
+// =======================
+// 每圈順序
+// =======================
+
+// lapCrossing 車手完成某圈的時間與與前車的差距
+type lapCrossing struct {
+	driver int
+	end    time.Time
+	gap    float64 // 與前車差距 (秒)，領先者為 0
+}
+
+// raceTimeline 每圈依完成時間排序的車手，以及每位車手各圈是否進站
+type raceTimeline struct {
+	totalLaps int
+	finish    time.Time            // 領先者衝線時間
+	laps      [][]lapCrossing      // 以圈數為索引
+	ends      map[int][]time.Time  // 車手各圈完成時間，零值表示沒有該圈
+	pitIn     map[int]map[int]bool // 車手在該圈結束時進站
+}
+
+func buildRaceTimeline(lapHistory map[int][]LapRecord) *raceTimeline {
+	tl := &raceTimeline{ends: make(map[int][]time.Time), pitIn: make(map[int]map[int]bool)}
+	for _, laps := range lapHistory {
+		if n := len(laps); n > 0 {
+			tl.totalLaps = max(tl.totalLaps, laps[n-1].LapNumber)
+		}
+	}
+	tl.laps = make([][]lapCrossing, tl.totalLaps+1)
+
+	for num, laps := range lapHistory {
+		ends := make([]time.Time, tl.totalLaps+1)
+		pits := make(map[int]bool)
+		for i, lap := range laps {
+			if i+1 < len(laps) && laps[i+1].LapNumber == lap.LapNumber+1 && laps[i+1].IsPitOutLap {
+				pits[lap.LapNumber] = true
+			}
+			if _, end, ok := lapTimeRange(laps, i); ok && lap.LapNumber > 0 {
+				ends[lap.LapNumber] = end
+				tl.laps[lap.LapNumber] = append(tl.laps[lap.LapNumber], lapCrossing{driver: num, end: end})
+			}
+		}
+		tl.ends[num] = ends
+		tl.pitIn[num] = pits
+	}
+
+	for _, crossings := range tl.laps {
+		sort.Slice(crossings, func(i, j int) bool { return crossings[i].end.Before(crossings[j].end) })
+		for i := 1; i < len(crossings); i++ {
+			crossings[i].gap = crossings[i].end.Sub(crossings[i-1].end).Seconds()
+		}
+	}
+	if last := tl.laps[tl.totalLaps]; len(last) > 0 {
+		tl.finish = last[0].end
+	}
+	return tl
+}
+
+// ahead 回傳在該圈先通過計時線的車手；任一車沒有該圈時為 0
+func (tl *raceTimeline) ahead(a, b, lap int) int {
+	ea, eb := tl.endOf(a, lap), tl.endOf(b, lap)
+	switch {
+	case ea.IsZero() || eb.IsZero():
+		return 0
+	case ea.Before(eb):
+		return a
+	default:
+		return b
+	}
+}
+
+func (tl *raceTimeline) endOf(driver, lap int) time.Time {
+	ends := tl.ends[driver]
+	if lap <= 0 || lap >= len(ends) {
+		return time.Time{}
+	}
+	return ends[lap]
+}
+
+// finished 車手完成該圈時領先者已衝線，即已接受方格旗
+func (tl *raceTimeline) finished(driver, lap int) bool {
+	end := tl.endOf(driver, lap)
+	return !tl.finish.IsZero() && !end.IsZero() && !end.Before(tl.finish)
+}
+
+// tookFlag 車手完成的最後一圈在領先者衝線之後，即跑完比賽；
+// 比賽在中立化下結束時，纏鬥的最後一圈會早於終點，不能以纏鬥的最後一圈判斷
+func (tl *raceTimeline) tookFlag(driver int) bool {
+	ends := tl.ends[driver]
+	for lap := len(ends) - 1; lap > 0; lap-- {
+		if !ends[lap].IsZero() {
+			return tl.finished(driver, lap)
+		}
+	}
+	return false
+}
+
+// pitted 車手在 lastLap 或 nextLap 結束時進站
+func (tl *raceTimeline) pitted(driver, lastLap, nextLap int) bool {
+	return tl.pitIn[driver][lastLap] || tl.pitIn[driver][nextLap]
+}
+
+// =======================
+// 偵測
+// =======================
+
+type battleState struct {
+	battle Battle
+	ahead  int
+	gapSum float64
+}
+
+type trainState struct {
+	train DRSTrain
+	seen  map[int]bool
+	last  []int // 最後一圈的車列順序
+}
+
+func detectBattles(lapHistory map[int][]LapRecord, neutralised []int, opts BattleOptions) *SessionBattles {
+	result := &SessionBattles{Options: opts, Battles: []Battle{}, Trains: []DRSTrain{}}
+	tl := buildRaceTimeline(lapHistory)
+
+	skip := make(map[int]bool, len(neutralised))
+	for _, lap := range neutralised {
+		skip[lap] = true
+	}
+
+	battles := make(map[[2]int]*battleState)
+	trains := make(map[int]*trainState)
+
+	closeBattle := func(key [2]int, st *battleState, next int) {
+		delete(battles, key)
+		b := &st.battle
+		if b.Laps < opts.MinLaps {
+			return
+		}
+		b.AvgGap = st.gapSum / float64(b.Laps)
+		a, d := b.Attacker, b.Defender
+		nextAhead := tl.ahead(a, d, next)
+		switch {
+		case tl.pitted(a, b.EndLap, next) || tl.pitted(d, b.EndLap, next):
+			b.Outcome = BattleOutcomePitDivergence
+		case nextAhead == 0 && tl.tookFlag(a) && tl.tookFlag(d):
+			b.Outcome = BattleOutcomeRaceEnd
+		case nextAhead == 0:
+			b.Outcome = BattleOutcomeRetirement
+		case nextAhead != d:
+			b.Outcome = BattleOutcomeOvertake
+			if st.ahead == d {
+				b.OvertakeLap = next
+			}
+		default:
+			b.Outcome = BattleOutcomeGapOpened
+		}
+		result.Battles = append(result.Battles, *b)
+	}
+
+	closeTrain := func(head int, st *trainState, next int) {
+		delete(trains, head)
+		t := &st.train
+		if t.Laps < opts.MinLaps {
+			return
+		}
+		overtaken := false
+		for _, num := range st.last[1:] {
+			if tl.ahead(head, num, next) == num {
+				overtaken = true
+				break
+			}
+		}
+		switch {
+		case tl.pitted(head, t.EndLap, next):
+			t.Outcome = BattleOutcomePitDivergence
+		case tl.endOf(head, next).IsZero() && tl.tookFlag(head):
+			t.Outcome = BattleOutcomeRaceEnd
+		case tl.endOf(head, next).IsZero():
+			t.Outcome = BattleOutcomeRetirement
+		case overtaken:
+			t.Outcome = BattleOutcomeOvertake
+		default:
+			t.Outcome = BattleOutcomeGapOpened
+		}
+		result.Trains = append(result.Trains, *t)
+	}
+
+	for lap := 1; lap <= tl.totalLaps; lap++ {
+		if skip[lap] {
+			continue
+		}
+		crossings := tl.laps[lap]
+		if len(crossings) == 0 {
+			continue
+		}
+
+		// 一對一纏鬥：相鄰且差距在門檻內
+		activePairs := make(map[[2]int]bool)
+		for i := 1; i < len(crossings); i++ {
+			c := crossings[i]
+			if c.gap > opts.MaxGap {
+				continue
+			}
+			front := crossings[i-1].driver
+			key := [2]int{min(front, c.driver), max(front, c.driver)}
+			activePairs[key] = true
+
+			st, ok := battles[key]
+			if !ok {
+				st = &battleState{
+					battle: Battle{Defender: front, Attacker: c.driver, StartLap: lap, MinGap: c.gap},
+					ahead:  front,
+				}
+				battles[key] = st
+			}
+			if st.ahead != front {
+				st.battle.OvertakeLap = lap
+				st.ahead = front
+			}
+			st.battle.EndLap = lap
+			st.battle.Laps++
+			st.battle.MinGap = min(st.battle.MinGap, c.gap)
+			st.gapSum += c.gap
+		}
+		for key, st := range battles {
+			if !activePairs[key] {
+				closeBattle(key, st, lap)
+			}
+		}
+
+		// DRS 車列：連續 3 車以上各自在前車門檻內，以車頭識別
+		activeHeads := make(map[int]bool)
+		for i := 0; i < len(crossings); {
+			j := i + 1
+			for j < len(crossings) && crossings[j].gap <= opts.MaxGap {
+				j++
+			}
+			if j-i >= drsTrainMinCars {
+				head := crossings[i].driver
+				activeHeads[head] = true
+				st, ok := trains[head]
+				if !ok {
+					st = &trainState{train: DRSTrain{Leader: head, StartLap: lap}, seen: make(map[int]bool)}
+					trains[head] = st
+				}
+				st.last = st.last[:0]
+				for _, c := range crossings[i:j] {
+					st.last = append(st.last, c.driver)
+					if !st.seen[c.driver] {
+						st.seen[c.driver] = true
+						st.train.Drivers = append(st.train.Drivers, c.driver)
+					}
+				}
+				st.train.EndLap = lap
+				st.train.Laps++
+				st.train.MaxCars = max(st.train.MaxCars, j-i)
+			}
+			i = j
+		}
+		for head, st := range trains {
+			if !activeHeads[head] {
+				closeTrain(head, st, lap)
+			}
+		}
+	}
+
+	// 比賽結束時仍在進行，沒有下一圈
+	for key, st := range battles {
+		closeBattle(key, st, 0)
+	}
+	for head, st := range trains {
+		closeTrain(head, st, 0)
+	}
+
+	sort.Slice(result.Battles, func(i, j int) bool {
+		a, b := result.Battles[i], result.Battles[j]
+		if a.StartLap != b.StartLap {
+			return a.StartLap < b.StartLap
+		}
+		return a.Defender < b.Defender
+	})
+	sort.Slice(result.Trains, func(i, j int) bool {
+		a, b := result.Trains[i], result.Trains[j]
+		if a.StartLap != b.StartLap {
+			return a.StartLap < b.StartLap
+		}
+		return a.Leader < b.Leader
+	})
+	return result
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// 彎道偵測參數
+const (
+	brakeMergeGap      = 30.0  // 兩段煞車間隔小於此距離 (m) 視為同一煞車區
+	minCornerSpeedDrop = 15    // 進彎到彎心至少降速 (km/h) 才算彎道
+	throttlePickup     = 20    // 彎心後油門超過此值視為重新給油
+	cornerMatchWindow  = 150.0 // 與參考圈彎心距離在此範圍內 (m) 視為同一彎
+	maxCornerDrivers   = 6
+)
+
+// firstArchiveYear OpenF1 最早有資料的年份
+const firstArchiveYear = 2023
+
+// circuitReferences circuit_key → 該賽道固定的參考圈；選定後不會再改變，跨請求快取
+var circuitReferences sync.Map
+
+// ErrInvalidCornerRequest 彎道分析的車手 / 圈數參數不合法
+var ErrInvalidCornerRequest = errors.New("invalid corner analysis request")
+
+// CornerAnalysisService 從 car_data 偵測煞車點、彎心速度、給油點與彎心檔位，並逐彎比較車手
+type CornerAnalysisService struct {
+	*BaseService
+}
+
+func NewCornerAnalysisService(base *BaseService) *CornerAnalysisService {
+	return &CornerAnalysisService{BaseService: base}
+}
+
+// detectedCorner 單圈偵測到的一個彎 (距離為該圈自身的積分距離)
+type detectedCorner struct {
+	brakeStart   float64
+	brakeEnd     float64
+	entrySpeed   int
+	apexDistance float64
+	apexSpeed    int
+	apexGear     int
+	pickup       float64
+}
+
+// =======================
+// 主入口: 逐彎比較
+// =======================
+
+// CompareCorners 以賽道固定的參考圈 (該賽道最早一場排位賽的最快圈) 編號彎道，再比較指定車手的圈；
+// 找不到時退回本 session 最快圈。laps 為 0 的車手使用其最快圈；差值以第一位車手為基準，正值表示較晚煞車 / 較快。
+func (s *CornerAnalysisService) CompareCorners(ctx context.Context, sessionKey int, drivers []int, laps []int) (*CornerAnalysis, error) {
+	if len(drivers) == 0 || len(drivers) > maxCornerDrivers {
+		return nil, fmt.Errorf("%w: between 1 and %d drivers required", ErrInvalidCornerRequest, maxCornerDrivers)
+	}
+	if len(laps) > 0 && len(laps) != len(drivers) {
+		return nil, fmt.Errorf("%w: laps must match drivers", ErrInvalidCornerRequest)
+	}
+
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, LapFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	reference, circuitKey, circuitRef, err := s.circuitReferenceLap(ctx, sessionKey, lapHistory)
+	if err != nil {
+		return nil, err
+	}
+	if !circuitRef {
+		var ok bool
+		if reference, ok = sessionFastestLap(lapHistory); !ok {
+			return nil, fmt.Errorf("no timed laps in session %d", sessionKey)
+		}
+		reference.SessionKey = sessionKey
+	}
+
+	refs := make([]TelemetryLapRef, len(drivers))
+	for i, driverNum := range drivers {
+		refs[i] = TelemetryLapRef{SessionKey: sessionKey, DriverNumber: driverNum}
+		if len(laps) > 0 && laps[i] > 0 {
+			refs[i].LapNumber = laps[i]
+			continue
+		}
+		fastest, ok := driverFastestLap(lapHistory[driverNum])
+		if !ok {
+			return nil, fmt.Errorf("%w: driver %d has no timed laps", ErrInvalidCornerRequest, driverNum)
+		}
+		refs[i].LapNumber = fastest.LapNumber
+	}
+
+	telemetry := NewTelemetryService(s.BaseService)
+	refTrace, err := telemetry.loadLapTrace(ctx, reference)
+	if err != nil {
+		return nil, fmt.Errorf("reference lap: %w", err)
+	}
+	if refTrace.length() <= 0 {
+		return nil, fmt.Errorf("reference lap has no distance")
+	}
+	refCorners := detectCorners(refTrace)
+
+	result := &CornerAnalysis{
+		SessionKey:        sessionKey,
+		CircuitKey:        circuitKey,
+		CircuitReference:  circuitRef,
+		Reference:         reference,
+		ReferenceDistance: refTrace.length(),
+	}
+	// 以參考圈偵測到的煞車區依序命名；同一賽道的參考圈固定，各 session 的煞車區編號一致。
+	// 平順通過的彎與合併的煞車區會讓編號與官方彎道編號不同，因此不使用 T1、T2 這類名稱
+	for i, c := range refCorners {
+		result.Corners = append(result.Corners, CornerComparison{
+			Number:       i + 1,
+			Name:         fmt.Sprintf("Zone%d", i+1),
+			ApexDistance: c.apexDistance,
+		})
+	}
+
+	for _, ref := range refs {
+		trace := refTrace
+		if ref != reference {
+			trace, err = telemetry.loadLapTrace(ctx, ref)
+			if err != nil {
+				s.Logger.Warn("Failed to load lap telemetry for corners", zap.Int("driver_number", ref.DriverNumber), zap.Int("lap_number", ref.LapNumber), zap.Error(err))
+				continue
+			}
+		}
+		if trace.length() <= 0 {
+			s.Logger.Warn("Lap telemetry has no distance", zap.Int("driver_number", ref.DriverNumber), zap.Int("lap_number", ref.LapNumber))
+			continue
+		}
+		result.Laps = append(result.Laps, ref)
+
+		// 各圈積分距離會有些微差異，先縮放到參考圈長度再比對
+		scale := refTrace.length() / trace.length()
+		matched := matchCorners(refCorners, detectCorners(trace), scale)
+		for i := range result.Corners {
+			dc := DriverCorner{DriverNumber: ref.DriverNumber, LapNumber: ref.LapNumber}
+			if c := matched[i]; c != nil {
+				dc.Detected = true
+				dc.BrakePoint = c.brakeStart * scale
+				dc.BrakingDistance = (c.brakeEnd - c.brakeStart) * scale
+				dc.EntrySpeed = c.entrySpeed
+				dc.ApexDistance = c.apexDistance * scale
+				dc.ApexSpeed = c.apexSpeed
+				dc.ApexGear = c.apexGear
+				dc.ThrottlePickup = c.pickup * scale
+			}
+			result.Corners[i].Drivers = append(result.Corners[i].Drivers, dc)
+		}
+	}
+
+	for i := range result.Corners {
+		compareToBaseline(result.Corners[i].Drivers)
+	}
+
+	return result, nil
+}
+
+// circuitReferenceLap 找出本 session 所在賽道最早一場排位賽的最快圈，作為固定的彎道編號基準；
+// 其他 session 的圈依圈長比例縮放後對應到這一圈的煞車區。找不到時 ok 為 false，只有請求取消時回傳錯誤
+func (s *CornerAnalysisService) circuitReferenceLap(ctx context.Context, sessionKey int, lapHistory map[int][]LapRecord) (ref TelemetryLapRef, circuitKey int, ok bool, err error) {
+	meetingKey := 0
+	for _, laps := range lapHistory {
+		if len(laps) > 0 {
+			meetingKey = laps[0].MeetingKey
+			break
+		}
+	}
+	if meetingKey == 0 {
+		return ref, 0, false, nil
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return ref, 0, false, err
+	}
+	sessions, err := fetchRecords[Session](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetSessionByMeeting(ctx, meetingKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch meeting sessions", zap.Int("meeting_key", meetingKey), zap.Error(err))
+		return ref, 0, false, nil
+	}
+	i := slices.IndexFunc(sessions, func(sess Session) bool { return sess.SessionKey == sessionKey })
+	if i < 0 {
+		return ref, 0, false, nil
+	}
+	current := sessions[i]
+	if cached, found := circuitReferences.Load(current.CircuitKey); found {
+		return cached.(TelemetryLapRef), current.CircuitKey, true, nil
+	}
+
+	for year := firstArchiveYear; year <= current.Year; year++ {
+		if err := s.throttle(ctx); err != nil {
+			return ref, current.CircuitKey, false, err
+		}
+		past, err := s.getPastSessions(ctx, year, "Qualifying")
+		if err != nil {
+			s.Logger.Warn("Failed to fetch past sessions", zap.Int("year", year), zap.Error(err))
+			continue
+		}
+		for _, sess := range past {
+			if sess.CircuitKey != current.CircuitKey {
+				continue
+			}
+			history := lapHistory
+			if sess.SessionKey != sessionKey {
+				if err := s.throttle(ctx); err != nil {
+					return ref, current.CircuitKey, false, err
+				}
+				if history, err = NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sess.SessionKey, LapFilter{}); err != nil {
+					s.Logger.Warn("Failed to fetch circuit reference laps", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+					continue
+				}
+			}
+			if ref, ok = sessionFastestLap(history); ok {
+				ref.SessionKey = sess.SessionKey
+				circuitReferences.Store(current.CircuitKey, ref)
+				return ref, current.CircuitKey, true, nil
+			}
+		}
+	}
+	return ref, current.CircuitKey, false, nil
+}
+
+// driverFastestLap 車手最快的有效圈；沒有 date_start 的圈無法界定遙測時間範圍，不列入
+func driverFastestLap(laps []LapRecord) (LapRecord, bool) {
+	var best LapRecord
+	for _, lap := range laps {
+		if lap.LapDuration <= 0 || lap.IsPitOutLap || lap.Deleted || lap.DateStart.IsZero() {
+			continue
+		}
+		if best.LapDuration == 0 || lap.LapDuration < best.LapDuration {
+			best = lap
+		}
+	}
+	return best, best.LapDuration > 0
+}
+
+func sessionFastestLap(lapHistory map[int][]LapRecord) (TelemetryLapRef, bool) {
+	var best LapRecord
+	for _, laps := range lapHistory {
+		lap, ok := driverFastestLap(laps)
+		if !ok {
+			continue
+		}
+		if best.LapDuration == 0 || lap.LapDuration < best.LapDuration ||
+			(lap.LapDuration == best.LapDuration && lap.DriverNumber < best.DriverNumber) {
+			best = lap
+		}
+	}
+	return TelemetryLapRef{DriverNumber: best.DriverNumber, LapNumber: best.LapNumber}, best.LapDuration > 0
+}
+
+// compareToBaseline 以第一位車手為基準計算煞車點與彎心速度差
+func compareToBaseline(drivers []DriverCorner) {
+	if len(drivers) == 0 || !drivers[0].Detected {
+		return
+	}
+	base := drivers[0]
+	for i := range drivers[1:] {
+		d := &drivers[i+1]
+		if !d.Detected {
+			continue
+		}
+		later := d.BrakePoint - base.BrakePoint
+		apexDelta := d.ApexSpeed - base.ApexSpeed
+		d.BrakeDelta = &later
+		d.ApexSpeedDelta = &apexDelta
+	}
+}
+
+// =======================
+// 彎道偵測
+// =======================
+
+// detectCorners 以煞車區找出彎道：彎心為煞車開始到下一個煞車區之間的最低速，
+// 給油點為彎心後第一個油門超過門檻的樣本。不踩煞車的高速彎不列入。
+func detectCorners(trace *lapTrace) []detectedCorner {
+	samples := trace.samples
+
+	type zone struct{ start, end int }
+	var zones []zone
+	for i := 0; i < len(samples); i++ {
+		if samples[i].data.Brake <= 0 {
+			continue
+		}
+		j := i
+		for j+1 < len(samples) && samples[j+1].data.Brake > 0 {
+			j++
+		}
+		if n := len(zones); n > 0 && samples[i].distance-samples[zones[n-1].end].distance < brakeMergeGap {
+			zones[n-1].end = j
+		} else {
+			zones = append(zones, zone{start: i, end: j})
+		}
+		i = j
+	}
+
+	var corners []detectedCorner
+	for k, z := range zones {
+		limit := len(samples)
+		if k+1 < len(zones) {
+			limit = zones[k+1].start
+		}
+
+		apex := z.start
+		for i := z.start; i < limit; i++ {
+			if samples[i].data.Speed < samples[apex].data.Speed {
+				apex = i
+			}
+		}
+		// 進彎速度取踩煞車前最後一筆樣本
+		entry := samples[max(z.start-1, 0)].data.Speed
+		if entry-samples[apex].data.Speed < minCornerSpeedDrop {
+			continue
+		}
+
+		pickup := samples[apex].distance
+		for i := apex; i < limit; i++ {
+			if samples[i].data.Throttle > throttlePickup {
+				pickup = samples[i].distance
+				break
+			}
+		}
+
+		corners = append(corners, detectedCorner{
+			brakeStart:   samples[z.start].distance,
+			brakeEnd:     samples[z.end].distance,
+			entrySpeed:   entry,
+			apexDistance: samples[apex].distance,
+			apexSpeed:    samples[apex].data.Speed,
+			apexGear:     samples[apex].data.NGear,
+			pickup:       pickup,
+		})
+	}
+	return corners
+}
+
+// matchCorners 依彎心距離把偵測到的彎對應到參考圈的彎，每個彎最多配對一次
+func matchCorners(reference, detected []detectedCorner, scale float64) []*detectedCorner {
+	type pair struct {
+		ref, det int
+		dist     float64
+	}
+	var pairs []pair
+	for i, rc := range reference {
+		for j, dc := range detected {
+			if d := math.Abs(dc.apexDistance*scale - rc.apexDistance); d <= cornerMatchWindow {
+				pairs = append(pairs, pair{ref: i, det: j, dist: d})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].dist < pairs[j].dist })
+
+	matched := make([]*detectedCorner, len(reference))
+	used := make(map[int]bool)
+	for _, p := range pairs {
+		if matched[p.ref] != nil || used[p.det] {
+			continue
+		}
+		matched[p.ref] = &detected[p.det]
+		used[p.det] = true
+	}
+	return matched
+}
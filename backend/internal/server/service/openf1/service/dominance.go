@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+
+	"go.uber.org/zap"
+)
+
+// 迷你分段數限制
+const (
+	DefaultMiniSectors = 25
+	minMiniSectors     = 3
+	maxMiniSectors     = 200
+)
+
+// defaultDominanceColor 車隊沒有代表色時使用的灰色
+const defaultDominanceColor = "888888"
+
+// teammateColorBlend 個別車手比較時，同隊第二位車手的顏色與白色混合的比例
+const teammateColorBlend = 0.5
+
+// ErrInvalidDominanceOptions 迷你分段設定不合法
+var ErrInvalidDominanceOptions = errors.New("invalid dominance options")
+
+// DominanceOptions 賽道優勢圖設定
+type DominanceOptions struct {
+	MiniSectors int   // 將賽道依距離等分的段數
+	ByTeam      bool  // 以車隊 (隊內最快) 比較，而非個別車手
+	Drivers     []int // 只比較這些車手，空白表示全部
+	LapFilter
+}
+
+// DominanceService 結合 location 與 car_data，找出每個迷你分段最快的車手或車隊
+type DominanceService struct {
+	*BaseService
+}
+
+func NewDominanceService(base *BaseService) *DominanceService {
+	return &DominanceService{BaseService: base}
+}
+
+// dominanceLap 參與比較的車手最快圈
+type dominanceLap struct {
+	driver Driver
+	lap    LapRecord
+	start  string
+	end    string
+	trace  *lapTrace
+}
+
+// =======================
+// 主入口: 賽道優勢圖
+// =======================
+
+// GetDominanceMap 以各車手最快圈計算每個迷你分段的通過時間；
+// 賽道形狀取自所有參與者中最快一圈的 location 資料。
+func (s *DominanceService) GetDominanceMap(ctx context.Context, sessionKey int, opts DominanceOptions) (*DominanceMap, error) {
+	if opts.MiniSectors < minMiniSectors || opts.MiniSectors > maxMiniSectors {
+		return nil, fmt.Errorf("%w: mini sectors must be between %d and %d", ErrInvalidDominanceOptions, minMiniSectors, maxMiniSectors)
+	}
+
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, opts.LapFilter)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	laps, err := s.loadFastestLaps(ctx, sessionKey, lapHistory, drivers, opts.Drivers)
+	if err != nil {
+		return nil, err
+	}
+	if len(laps) == 0 {
+		return nil, fmt.Errorf("no lap telemetry available for session %d", sessionKey)
+	}
+
+	reference := laps[0]
+	for _, l := range laps[1:] {
+		if l.lap.LapDuration < reference.lap.LapDuration {
+			reference = l
+		}
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	locations, err := fetchRecords[datasource.Location](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetLocationByLap(ctx, sessionKey, reference.driver.DriverNumber, url.QueryEscape(reference.start), url.QueryEscape(reference.end))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get location failed: %w", err)
+	}
+
+	length := reference.trace.length()
+	result := &DominanceMap{
+		SessionKey:  sessionKey,
+		MiniSectors: opts.MiniSectors,
+		ByTeam:      opts.ByTeam,
+		Reference:   TelemetryLapRef{SessionKey: sessionKey, DriverNumber: reference.driver.DriverNumber, LapNumber: reference.lap.LapNumber},
+		Distance:    length,
+	}
+
+	segmentPoints := trackSegments(reference, locations, length, opts.MiniSectors)
+	participants := make(map[string]*DominanceParticipant)
+	var order []string
+	teamDrivers := make(map[string]int)
+
+	for k := 0; k < opts.MiniSectors; k++ {
+		from := length * float64(k) / float64(opts.MiniSectors)
+		to := length * float64(k+1) / float64(opts.MiniSectors)
+
+		segment := DominanceSegment{
+			Index:         k + 1,
+			StartDistance: from,
+			EndDistance:   to,
+			Points:        segmentPoints[k],
+		}
+
+		best := make(map[string]float64)
+		for _, l := range laps {
+			scale := l.trace.length() / length
+			t := l.trace.atDistance(to*scale).time - l.trace.atDistance(from*scale).time
+
+			key, label := participantKey(l.driver, opts.ByTeam)
+			if _, ok := participants[key]; !ok {
+				color := l.driver.Color
+				if color == "" {
+					color = defaultDominanceColor
+				}
+				// 隊友共用車隊顏色時地圖無法區分，第二位車手改用較淺的顏色
+				if !opts.ByTeam {
+					if teamDrivers[l.driver.Team] > 0 {
+						color = blendWithWhite(color, teammateColorBlend)
+					}
+					teamDrivers[l.driver.Team]++
+				}
+				participants[key] = &DominanceParticipant{Key: key, Label: label, Color: color}
+				order = append(order, key)
+			}
+			if cur, ok := best[key]; !ok || t < cur {
+				best[key] = t
+			}
+		}
+
+		for key, t := range best {
+			segment.Times = append(segment.Times, SegmentTime{Key: key, Time: t})
+		}
+		sort.Slice(segment.Times, func(i, j int) bool {
+			if segment.Times[i].Time != segment.Times[j].Time {
+				return segment.Times[i].Time < segment.Times[j].Time
+			}
+			return segment.Times[i].Key < segment.Times[j].Key
+		})
+		winner := participants[segment.Times[0].Key]
+		winner.SegmentsWon++
+		segment.Winner = winner.Key
+		segment.Color = winner.Color
+		if len(segment.Times) > 1 {
+			segment.Margin = segment.Times[1].Time - segment.Times[0].Time
+		}
+
+		result.Segments = append(result.Segments, segment)
+	}
+
+	for _, key := range order {
+		p := participants[key]
+		p.Share = float64(p.SegmentsWon) / float64(opts.MiniSectors) * 100
+		result.Participants = append(result.Participants, *p)
+	}
+	sort.SliceStable(result.Participants, func(i, j int) bool {
+		return result.Participants[i].SegmentsWon > result.Participants[j].SegmentsWon
+	})
+
+	return result, nil
+}
+
+// blendWithWhite 把 6 位 hex 色碼與白色依比例混合；無法解析時原樣回傳
+func blendWithWhite(color string, ratio float64) string {
+	v, err := strconv.ParseUint(color, 16, 32)
+	if err != nil || len(color) != 6 {
+		return color
+	}
+	var out [3]uint64
+	for i := range out {
+		c := float64(v >> (16 - 8*i) & 0xff)
+		out[i] = uint64(c + (255-c)*ratio + 0.5)
+	}
+	return fmt.Sprintf("%02x%02x%02x", out[0], out[1], out[2])
+}
+
+func participantKey(d Driver, byTeam bool) (string, string) {
+	if byTeam {
+		return d.Team, d.Team
+	}
+	label := d.NameAcronym
+	if label == "" {
+		label = strconv.Itoa(d.DriverNumber)
+	}
+	return strconv.Itoa(d.DriverNumber), label
+}
+
+// loadFastestLaps 逐一撈取每位車手最快圈的遙測；無法取得者略過，請求取消時回傳錯誤
+func (s *DominanceService) loadFastestLaps(ctx context.Context, sessionKey int, lapHistory map[int][]LapRecord, drivers map[int]Driver, only []int) ([]dominanceLap, error) {
+	numbers := only
+	if len(numbers) == 0 {
+		for num := range lapHistory {
+			numbers = append(numbers, num)
+		}
+		sort.Ints(numbers)
+	}
+
+	telemetry := NewTelemetryService(s.BaseService)
+	var laps []dominanceLap
+	for _, num := range numbers {
+		history := lapHistory[num]
+		fastest, ok := driverFastestLap(history)
+		if !ok {
+			continue
+		}
+		driver, ok := drivers[num]
+		if !ok {
+			driver = Driver{DriverNumber: num}
+		}
+		if driver.Team == "" {
+			driver.Team = strconv.Itoa(num)
+		}
+
+		start, end := lapWindow(history, fastest)
+		if err := s.throttle(ctx); err != nil {
+			return nil, err
+		}
+		data, err := telemetry.getLapCarDataInWindow(ctx, sessionKey, num, fastest.LapNumber, start, end)
+		if err != nil {
+			s.Logger.Warn("Failed to fetch lap telemetry for dominance", zap.Int("driver_number", num), zap.Error(err))
+			continue
+		}
+		trace, err := buildLapTrace(data)
+		if err != nil {
+			s.Logger.Warn("Failed to build lap trace for dominance", zap.Int("driver_number", num), zap.Error(err))
+			continue
+		}
+		laps = append(laps, dominanceLap{driver: driver, lap: fastest, start: start, end: end, trace: trace})
+	}
+	return laps, nil
+}
+
+// lapWindow 圈的起訖時間：下一圈的開始，沒有下一圈時以圈速推算
+func lapWindow(history []LapRecord, lap LapRecord) (string, string) {
+	end := lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second)))
+	for _, l := range history {
+		if l.LapNumber == lap.LapNumber+1 && !l.DateStart.IsZero() {
+			end = l.DateStart
+			break
+		}
+	}
+	return lap.DateStart.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano)
+}
+
+// trackSegments 以參考圈的時間 → 距離對應，把 location 點分到各迷你分段；
+// 每段多帶下一段的第一個點讓線段相連
+func trackSegments(reference dominanceLap, locations []datasource.Location, length float64, n int) [][][2]int {
+	start, _ := parseSampleTime(reference.start)
+
+	type located struct {
+		distance float64
+		point    [2]int
+	}
+	var points []located
+	for _, loc := range locations {
+		ts, err := parseSampleTime(loc.Date)
+		if err != nil {
+			continue
+		}
+		sec := ts.Sub(start).Seconds()
+		if sec < 0 {
+			continue
+		}
+		points = append(points, located{distance: reference.trace.atTime(sec).distance, point: [2]int{loc.X, loc.Y}})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].distance < points[j].distance })
+
+	segments := make([][][2]int, n)
+	for i, p := range points {
+		k := min(int(p.distance/length*float64(n)), n-1)
+		segments[k] = append(segments[k], p.point)
+		if i+1 < len(points) {
+			if next := min(int(points[i+1].distance/length*float64(n)), n-1); next != k {
+				segments[k] = append(segments[k], points[i+1].point)
+			}
+		}
+	}
+	return segments
+}
+
+// =======================
+// SVG 輸出
+// =======================
+
+// RenderDominanceSVG 將優勢圖畫成 SVG：每個迷你分段以勝出者顏色著色，左上角為圖例
+func RenderDominanceSVG(m *DominanceMap, width int) []byte {
+	const padding = 20.0
+	const legendLine = 18.0
+
+	minX, minY, maxX, maxY := 0, 0, 0, 0
+	first := true
+	for _, seg := range m.Segments {
+		for _, p := range seg.Points {
+			if first {
+				minX, maxX, minY, maxY = p[0], p[0], p[1], p[1]
+				first = false
+				continue
+			}
+			minX, maxX = min(minX, p[0]), max(maxX, p[0])
+			minY, maxY = min(minY, p[1]), max(maxY, p[1])
+		}
+	}
+
+	span := float64(max(maxX-minX, maxY-minY, 1))
+	scale := (float64(width) - 2*padding) / span
+	legendHeight := legendLine * float64(len(m.Participants))
+	height := float64(maxY-minY)*scale + 2*padding + legendHeight
+
+	// OpenF1 的 y 軸向上，SVG 向下，需翻轉
+	project := func(p [2]int) (float64, float64) {
+		return padding + float64(p[0]-minX)*scale, padding + legendHeight + float64(maxY-p[1])*scale
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%.0f" viewBox="0 0 %d %.0f">`, width, height, width, height)
+	b.WriteString(`<rect width="100%" height="100%" fill="#15151e"/>`)
+	for _, seg := range m.Segments {
+		if len(seg.Points) < 2 {
+			continue
+		}
+		coords := make([]string, len(seg.Points))
+		for i, p := range seg.Points {
+			x, y := project(p)
+			coords[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="#%s" stroke-width="6" stroke-linecap="round"/>`,
+			strings.Join(coords, " "), html.EscapeString(seg.Color))
+	}
+	for i, p := range m.Participants {
+		y := padding + legendLine*float64(i)
+		fmt.Fprintf(&b, `<rect x="%.0f" y="%.0f" width="12" height="12" fill="#%s"/>`, padding, y, html.EscapeString(p.Color))
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" fill="#ffffff" font-family="sans-serif" font-size="12">%s (%d)</text>`,
+			padding+18, y+11, html.EscapeString(p.Label), p.SegmentsWon)
+	}
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
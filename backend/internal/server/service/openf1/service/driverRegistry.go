@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DriverRegistryService 依 (賽季, session, 車號) 解析車手身分與車隊。
+// 車號會被不同車手重複使用、車手也可能在賽季中轉隊，因此一律以該 session 的車手名單為準。
+type DriverRegistryService struct {
+	*BaseService
+}
+
+func NewDriverRegistryService(base *BaseService) *DriverRegistryService {
+	return &DriverRegistryService{BaseService: base}
+}
+
+// DriverRegistry 賽季內 session → 車號 → 車手的對照表
+type DriverRegistry struct {
+	Year      int
+	sessions  []int // 依時間排序
+	bySession map[int]map[int]Driver
+}
+
+// Lookup 回傳指定 session 的車手；該 session 名單缺漏時退回賽季內最後一次出現的身分
+func (r *DriverRegistry) Lookup(sessionKey, driverNumber int) (Driver, bool) {
+	if drivers, ok := r.bySession[sessionKey]; ok {
+		if d, ok := drivers[driverNumber]; ok {
+			return d, true
+		}
+	}
+	return r.Latest(driverNumber)
+}
+
+// Latest 回傳該車號在本賽季最後一次出現時的身分與車隊
+func (r *DriverRegistry) Latest(driverNumber int) (Driver, bool) {
+	for i := len(r.sessions) - 1; i >= 0; i-- {
+		if d, ok := r.bySession[r.sessions[i]][driverNumber]; ok {
+			return d, true
+		}
+	}
+	return Driver{}, false
+}
+
+// Drivers 回傳賽季內所有車手與其車隊變動
+func (r *DriverRegistry) Drivers() []SeasonDriver {
+	index := make(map[int]*SeasonDriver)
+	for _, sessionKey := range r.sessions {
+		for number, d := range r.bySession[sessionKey] {
+			sd, ok := index[number]
+			if !ok {
+				sd = &SeasonDriver{DriverNumber: number}
+				index[number] = sd
+			}
+			sd.FullName = d.FullName
+			sd.NameAcronym = d.NameAcronym
+			sd.HeadShotURL = d.HeadShotURL
+
+			last := len(sd.Teams) - 1
+			if last >= 0 && sd.Teams[last].TeamName == d.Team {
+				sd.Teams[last].LastSessionKey = sessionKey
+				continue
+			}
+			sd.Teams = append(sd.Teams, SeasonDriverTeam{
+				TeamName:        d.Team,
+				Color:           d.Color,
+				FirstSessionKey: sessionKey,
+				LastSessionKey:  sessionKey,
+			})
+		}
+	}
+
+	drivers := make([]SeasonDriver, 0, len(index))
+	for _, sd := range index {
+		drivers = append(drivers, *sd)
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i].DriverNumber < drivers[j].DriverNumber })
+	return drivers
+}
+
+// =======================
+// 建立賽季對照表
+// =======================
+
+// GetSeasonRegistry 以該年度所有 Race/Sprint session 的車手名單建立對照表
+func (s *DriverRegistryService) GetSeasonRegistry(ctx context.Context, year int) (*DriverRegistry, error) {
+	sessions, err := s.getPastSessions(ctx, year, "Race", "Sprint")
+	if err != nil {
+		return nil, err
+	}
+	return s.BuildRegistry(ctx, year, sessions)
+}
+
+// BuildRegistry 抓取每個 session 的車手名單建立對照表；個別 session 失敗只記錄警告
+func (s *DriverRegistryService) BuildRegistry(ctx context.Context, year int, sessions []Session) (*DriverRegistry, error) {
+	ordered := append([]Session(nil), sessions...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].DateStart < ordered[j].DateStart })
+
+	registry := &DriverRegistry{
+		Year:      year,
+		bySession: make(map[int]map[int]Driver, len(ordered)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+
+	for _, sess := range ordered {
+		registry.sessions = append(registry.sessions, sess.SessionKey)
+
+		if drivers, ok := driverListCache.get(sess.SessionKey); ok {
+			registry.bySession[sess.SessionKey] = drivers
+			continue
+		}
+
+		wg.Add(1)
+		go func(sessionKey int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			err := s.throttle(ctx)
+			var drivers map[int]Driver
+			if err == nil {
+				drivers, err = s.fetchSessionDrivers(ctx, sessionKey)
+			}
+			<-semaphore
+
+			if err != nil {
+				s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+				return
+			}
+
+			mu.Lock()
+			registry.bySession[sessionKey] = drivers
+			mu.Unlock()
+		}(sess.SessionKey)
+	}
+
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(ordered) > 0 && len(registry.bySession) == 0 {
+		return nil, fmt.Errorf("no driver lists available for %d", year)
+	}
+	return registry, nil
+}
+
+// GetSessionDrivers 回傳單一 session 的車手名單 (車號 → 車手)
+func (s *DriverRegistryService) GetSessionDrivers(ctx context.Context, sessionKey int) (map[int]Driver, error) {
+	if drivers, ok := driverListCache.get(sessionKey); ok {
+		return drivers, nil
+	}
+	return s.fetchSessionDrivers(ctx, sessionKey)
+}
+
+func (s *DriverRegistryService) fetchSessionDrivers(ctx context.Context, sessionKey int) (map[int]Driver, error) {
+	data, err := s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetSessionsDrivers(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Driver
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	drivers := make(map[int]Driver, len(list))
+	for _, d := range list {
+		drivers[d.DriverNumber] = d
+	}
+	if len(drivers) > 0 {
+		driverListCache.set(sessionKey, drivers)
+	}
+	return drivers, nil
+}
+
+// =======================
+// Session 車手名單快取
+// =======================
+
+const driverListCacheTTL = time.Hour
+
+// driverListCache 跨請求共用；已結束 session 的名單不會再變動
+var driverListCache = &sessionDriverCache{entries: make(map[int]cachedDrivers)}
+
+type cachedDrivers struct {
+	drivers   map[int]Driver
+	fetchedAt time.Time
+}
+
+type sessionDriverCache struct {
+	mu      sync.RWMutex
+	entries map[int]cachedDrivers
+}
+
+func (c *sessionDriverCache) get(sessionKey int) (map[int]Driver, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[sessionKey]
+	if !ok || time.Since(entry.fetchedAt) > driverListCacheTTL {
+		return nil, false
+	}
+	return entry.drivers, true
+}
+
+func (c *sessionDriverCache) set(sessionKey int, drivers map[int]Driver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[sessionKey] = cachedDrivers{drivers: drivers, fetchedAt: time.Now()}
+}
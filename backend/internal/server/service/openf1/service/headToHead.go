@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HeadToHeadService 比較賽季中每組隊友的排位、正賽、積分、領跑圈數與 DNF
+type HeadToHeadService struct {
+	*BaseService
+}
+
+func NewHeadToHeadService(base *BaseService) *HeadToHeadService {
+	return &HeadToHeadService{BaseService: base}
+}
+
+// weekendData 單一大獎賽週末計算 H2H 所需的資料
+type weekendData struct {
+	meetingKey int
+	location   string
+	drivers    map[int]Driver // 正賽車手名單 (決定隊友組合)
+	qualifying map[int]QualifyingResult
+	race       map[int]SessionResult
+	sprint     map[int]SessionResult
+	grid       map[int]StartingGridRecord
+	lapsLed    map[int]int
+	hasQuali   bool
+	hasSprint  bool
+}
+
+// =======================
+// 主入口: 賽季隊友對決
+// =======================
+func (s *HeadToHeadService) GetSeasonHeadToHead(ctx context.Context, year int) (*SeasonHeadToHead, error) {
+	sessions, err := s.getPastSessions(ctx, year, "Race", "Sprint", "Qualifying")
+	if err != nil {
+		return nil, err
+	}
+
+	// 依 meeting 分組，只處理已有正賽的週末
+	byMeeting := make(map[int]map[string]Session)
+	var meetings []int
+	for _, sess := range sessions {
+		if _, ok := byMeeting[sess.MeetingKey]; !ok {
+			byMeeting[sess.MeetingKey] = make(map[string]Session)
+			meetings = append(meetings, sess.MeetingKey)
+		}
+		byMeeting[sess.MeetingKey][sess.SessionName] = sess
+	}
+
+	weekends := make([]*weekendData, len(meetings))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, meetingKey := range meetings {
+		race, ok := byMeeting[meetingKey]["Race"]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, sessions map[string]Session, race Session) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			weekend, err := s.loadWeekend(ctx, sessions, race)
+			if err != nil {
+				s.Logger.Warn("Failed to load weekend for head-to-head", zap.Int("meeting_key", race.MeetingKey), zap.Error(err))
+				return
+			}
+			weekends[i] = weekend
+		}(i, byMeeting[meetingKey], race)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[[2]int]*TeammateHeadToHead)
+	qualiGaps := make(map[[2]int][]float64)
+	qualiGapPcts := make(map[[2]int][]float64)
+	var order [][2]int
+
+	for _, w := range weekends {
+		if w == nil {
+			continue
+		}
+		for _, pair := range teammatePairs(w.drivers) {
+			a, b := w.drivers[pair[0]], w.drivers[pair[1]]
+			key := [2]int{a.DriverNumber, b.DriverNumber}
+			h2h, ok := pairs[key]
+			if !ok {
+				h2h = &TeammateHeadToHead{
+					TeamName: a.Team,
+					Color:    a.Color,
+					DriverA:  HeadToHeadDriver{DriverNumber: a.DriverNumber, FullName: a.FullName, NameAcronym: a.NameAcronym},
+					DriverB:  HeadToHeadDriver{DriverNumber: b.DriverNumber, FullName: b.FullName, NameAcronym: b.NameAcronym},
+				}
+				pairs[key] = h2h
+				order = append(order, key)
+			}
+
+			round := compareWeekend(w, &h2h.DriverA, &h2h.DriverB)
+			h2h.Weekends++
+			h2h.Rounds = append(h2h.Rounds, round)
+			if round.QualifyingGap != nil {
+				qualiGaps[key] = append(qualiGaps[key], *round.QualifyingGap)
+				qualiGapPcts[key] = append(qualiGapPcts[key], *round.QualifyingGapPct)
+			}
+		}
+	}
+
+	result := &SeasonHeadToHead{Year: year}
+	for _, key := range order {
+		h2h := pairs[key]
+		h2h.QualifyingMedianGap = median(qualiGaps[key])
+		h2h.QualifyingMedianGapPct = median(qualiGapPcts[key])
+		if total := h2h.DriverA.Points + h2h.DriverB.Points; total > 0 {
+			h2h.DriverA.PointsShare = h2h.DriverA.Points / total * 100
+			h2h.DriverB.PointsShare = h2h.DriverB.Points / total * 100
+		}
+		result.Pairs = append(result.Pairs, *h2h)
+	}
+	sort.SliceStable(result.Pairs, func(i, j int) bool {
+		return result.Pairs[i].TeamName < result.Pairs[j].TeamName
+	})
+
+	return result, nil
+}
+
+// =======================
+// 資料載入
+// =======================
+
+func (s *HeadToHeadService) loadWeekend(ctx context.Context, sessions map[string]Session, race Session) (*weekendData, error) {
+	w := &weekendData{
+		meetingKey: race.MeetingKey,
+		location:   race.Location,
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, race.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	w.drivers = drivers
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	raceResults, err := fetchRecords[SessionResult](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetResultBySession(ctx, race.SessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.race = indexResults(raceResults)
+
+	if quali, ok := sessions["Qualifying"]; ok {
+		if err := s.throttle(ctx); err != nil {
+			return nil, err
+		}
+		results, err := fetchRecords[QualifyingResult](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+			return s.DS.GetResultBySession(ctx, quali.SessionKey)
+		})
+		if err != nil {
+			s.Logger.Warn("Failed to fetch qualifying results", zap.Int("session_key", quali.SessionKey), zap.Error(err))
+		} else {
+			w.qualifying = make(map[int]QualifyingResult, len(results))
+			for _, r := range results {
+				w.qualifying[r.DriverNumber] = r
+			}
+			w.hasQuali = len(results) > 0
+		}
+	}
+
+	if sprint, ok := sessions["Sprint"]; ok {
+		if err := s.throttle(ctx); err != nil {
+			return nil, err
+		}
+		results, err := fetchRecords[SessionResult](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+			return s.DS.GetResultBySession(ctx, sprint.SessionKey)
+		})
+		if err != nil {
+			s.Logger.Warn("Failed to fetch sprint results", zap.Int("session_key", sprint.SessionKey), zap.Error(err))
+		} else {
+			w.sprint = indexResults(results)
+			w.hasSprint = len(results) > 0
+		}
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	grid, err := fetchRecords[StartingGridRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetStartGridBySession(ctx, race.SessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch starting grid", zap.Int("session_key", race.SessionKey), zap.Error(err))
+	} else {
+		w.grid = make(map[int]StartingGridRecord, len(grid))
+		for _, g := range grid {
+			w.grid[g.DriverNumber] = g
+		}
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	laps, err := fetchRecords[LapRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetLapsBySession(ctx, race.SessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch race laps", zap.Int("session_key", race.SessionKey), zap.Error(err))
+	} else {
+		w.lapsLed = lapsLedByDriver(laps)
+	}
+
+	return w, nil
+}
+
+func indexResults(results []SessionResult) map[int]SessionResult {
+	index := make(map[int]SessionResult, len(results))
+	for _, r := range results {
+		index[r.DriverNumber] = r
+	}
+	return index
+}
+
+// teammatePairs 依車隊分組，回傳每組隊友 (車號小者在前)
+func teammatePairs(drivers map[int]Driver) [][2]int {
+	byTeam := make(map[string][]int)
+	for number, d := range drivers {
+		if d.Team == "" {
+			continue
+		}
+		byTeam[d.Team] = append(byTeam[d.Team], number)
+	}
+
+	var pairs [][2]int
+	for _, numbers := range byTeam {
+		sort.Ints(numbers)
+		for i := 0; i < len(numbers); i++ {
+			for j := i + 1; j < len(numbers); j++ {
+				pairs = append(pairs, [2]int{numbers[i], numbers[j]})
+			}
+		}
+	}
+	return pairs
+}
+
+// lapsLedByDriver 每圈最先完成該圈的車手視為領跑。圈的結束時間取下一圈的 date_start，
+// 沒有下一圈時以 date_start + lap_duration 推算；OpenF1 第 1 圈通常沒有 date_start，需靠第 2 圈推得
+func lapsLedByDriver(laps []LapRecord) map[int]int {
+	type lapEnd struct {
+		driver int
+		end    time.Time
+	}
+	starts := make(map[[2]int]time.Time)
+	for _, lap := range laps {
+		if !lap.DateStart.IsZero() {
+			starts[[2]int{lap.DriverNumber, lap.LapNumber}] = lap.DateStart
+		}
+	}
+
+	leaders := make(map[int]lapEnd)
+	for _, lap := range laps {
+		end, ok := starts[[2]int{lap.DriverNumber, lap.LapNumber + 1}]
+		if !ok {
+			if lap.DateStart.IsZero() || lap.LapDuration <= 0 {
+				continue
+			}
+			end = lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second)))
+		}
+		if cur, ok := leaders[lap.LapNumber]; !ok || end.Before(cur.end) {
+			leaders[lap.LapNumber] = lapEnd{driver: lap.DriverNumber, end: end}
+		}
+	}
+
+	led := make(map[int]int)
+	for _, l := range leaders {
+		led[l.driver]++
+	}
+	return led
+}
+
+// =======================
+// 單站比較
+// =======================
+
+func compareWeekend(w *weekendData, a, b *HeadToHeadDriver) HeadToHeadRound {
+	round := HeadToHeadRound{
+		MeetingKey: w.meetingKey,
+		Location:   w.location,
+	}
+
+	// 排位：名次較前者勝，只有一方有名次時該方勝
+	if w.hasQuali {
+		qa, qb := w.qualifying[a.DriverNumber], w.qualifying[b.DriverNumber]
+		round.QualifyingWinner = betterPosition(a.DriverNumber, qa.Position, b.DriverNumber, qb.Position)
+		if gap, pct, ok := qualifyingGap(qa, qb); ok {
+			round.QualifyingGap = &gap
+			round.QualifyingGapPct = &pct
+		}
+	}
+
+	// 發車格 (含處罰後)
+	if len(w.grid) > 0 {
+		round.GridWinner = betterPosition(a.DriverNumber, w.grid[a.DriverNumber].Position, b.DriverNumber, w.grid[b.DriverNumber].Position)
+	}
+
+	// 正賽：完賽名次較前者勝，兩人都未完賽則不計
+	ra, rb := w.race[a.DriverNumber], w.race[b.DriverNumber]
+	round.RaceWinner = betterPosition(a.DriverNumber, classifiedPosition(ra), b.DriverNumber, classifiedPosition(rb))
+
+	for _, x := range []struct {
+		driver *HeadToHeadDriver
+		race   SessionResult
+	}{{a, ra}, {b, rb}} {
+		d := x.driver
+		d.Points += x.race.Points
+		if w.hasSprint {
+			d.Points += w.sprint[d.DriverNumber].Points
+		}
+		if x.race.DNF {
+			d.DNFs++
+		}
+		d.LapsLed += w.lapsLed[d.DriverNumber]
+
+		if round.QualifyingWinner == d.DriverNumber {
+			d.QualifyingWins++
+		}
+		if round.GridWinner == d.DriverNumber {
+			d.GridWins++
+		}
+		if round.RaceWinner == d.DriverNumber {
+			d.RaceWins++
+		}
+	}
+
+	return round
+}
+
+// classifiedPosition 正賽有效名次，DNF/DNS/DSQ 回傳 0
+func classifiedPosition(r SessionResult) int {
+	if r.DNF || r.DNS || r.DSQ {
+		return 0
+	}
+	return r.Position
+}
+
+// betterPosition 回傳名次較前的車手，名次 <= 0 視為無名次；兩人都無名次回傳 0
+func betterPosition(driverA, posA, driverB, posB int) int {
+	switch {
+	case posA > 0 && (posB <= 0 || posA < posB):
+		return driverA
+	case posB > 0 && (posA <= 0 || posB < posA):
+		return driverB
+	}
+	return 0
+}
+
+// qualifyingGap 以兩人都有成績的最後一節 (Q3 > Q2 > Q1) 計算差距 (a - b，秒與百分比)
+func qualifyingGap(a, b QualifyingResult) (float64, float64, bool) {
+	for i := min(len(a.Duration), len(b.Duration)) - 1; i >= 0; i-- {
+		ta, tb := a.Duration[i], b.Duration[i]
+		if ta == nil || tb == nil || *ta <= 0 || *tb <= 0 {
+			continue
+		}
+		gap := *ta - *tb
+		return gap, gap / *tb * 100, true
+	}
+	return 0, 0, false
+}
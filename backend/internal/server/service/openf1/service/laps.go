@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"sort"
+
+	"go.uber.org/zap"
 )
 
 type LapService struct {
@@ -42,3 +44,82 @@ func (s *LapService) GetLapHistoryAll(ctx context.Context, sessionKey int) (map[
 
 	return driverHistory, nil
 }
+
+// GetMarkedLapHistory 回傳已標記中立化類型與刪除圈的圈資料，以及中立化期間；
+// race control 取得失敗時圈不標記
+func (s *LapService) GetMarkedLapHistory(ctx context.Context, sessionKey int) (map[int][]LapRecord, []NeutralisationPeriod, error) {
+	lapHistory, err := s.GetLapHistoryAll(ctx, sessionKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch race control, laps will not be marked", zap.Int("session_key", sessionKey), zap.Error(err))
+		return lapHistory, nil, nil
+	}
+
+	events := ParseRaceControlEvents(records)
+	periods := ExtractNeutralisations(events)
+	markNeutralisedLaps(lapHistory, periods)
+	markDeletedLaps(lapHistory, events)
+	return lapHistory, periods, nil
+}
+
+// GetFilteredLapHistory 各分析服務共用的圈資料：一律標記刪除圈，並依 LapFilter 排除中立化圈
+func (s *LapService) GetFilteredLapHistory(ctx context.Context, sessionKey int, filter LapFilter) (map[int][]LapRecord, error) {
+	lapHistory, _, err := s.GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if !filter.ExcludeNeutralised {
+		return lapHistory, nil
+	}
+
+	for driver, laps := range lapHistory {
+		kept := laps[:0]
+		for _, lap := range laps {
+			if lap.Neutralised == "" {
+				kept = append(kept, lap)
+			}
+		}
+		lapHistory[driver] = kept
+	}
+	return lapHistory, nil
+}
+
+// GetDriverLaps 單一車手的 OpenF1 原始圈資料，加上 deleted、deleted_reason 與 neutralised 欄位，
+// 標記方式與 GetMarkedLapHistory 相同；其餘欄位原樣保留 (包含 null)。race control 取得失敗時不標記
+func (s *LapService) GetDriverLaps(ctx context.Context, sessionKey, driverNum int) ([]byte, error) {
+	records, err := fetchRecords[map[string]json.RawMessage](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetLapsByDriver(ctx, sessionKey, driverNum)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	marked := make(map[int]LapRecord)
+	lapHistory, _, err := s.GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to mark driver laps", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+	for _, lap := range lapHistory[driverNum] {
+		marked[lap.LapNumber] = lap
+	}
+
+	for _, rec := range records {
+		var lapNumber int
+		_ = json.Unmarshal(rec["lap_number"], &lapNumber)
+		lap := marked[lapNumber]
+		rec["deleted"], _ = json.Marshal(lap.Deleted)
+		if lap.Deleted {
+			rec["deleted_reason"], _ = json.Marshal(lap.DeletedReason)
+		}
+		if lap.Neutralised != "" {
+			rec["neutralised"], _ = json.Marshal(lap.Neutralised)
+		}
+	}
+	return json.Marshal(records)
+}
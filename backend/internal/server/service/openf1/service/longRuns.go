@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// LongRunOptions 長距離模擬的判定設定
+type LongRunOptions struct {
+	MinLaps                 int     `json:"min_laps"`                 // 長距離至少需要的代表圈數
+	RepresentativeThreshold float64 `json:"representative_threshold"` // 不慢於該 stint 最快圈此倍數才算代表圈
+	MaxSlowLaps             int     `json:"max_slow_laps"`            // 容許連續的慢圈 (塞車) 數，超過即中斷
+	MinRepresentativeShare  float64 `json:"min_representative_share"` // 代表圈佔該段總圈數的最低比例
+	FuelCorrection          float64 `json:"fuel_correction"`          // 每圈燃油修正秒數，修正到該段開始時的油量
+	LapFilter
+}
+
+func DefaultLongRunOptions() LongRunOptions {
+	return LongRunOptions{
+		MinLaps:                 5,
+		RepresentativeThreshold: 1.05,
+		MaxSlowLaps:             1,
+		MinRepresentativeShare:  0.8,
+		FuelCorrection:          0.06,
+	}
+}
+
+type LongRunService struct {
+	*BaseService
+}
+
+func NewLongRunService(base *BaseService) *LongRunService {
+	return &LongRunService{BaseService: base}
+}
+
+// =======================
+// 主入口: 練習賽長距離
+// =======================
+
+// GetSessionLongRuns 找出單一 session 每位車手的長距離模擬
+func (s *LongRunService) GetSessionLongRuns(ctx context.Context, sessionKey int, opts LongRunOptions) (*SessionLongRuns, error) {
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, opts.LapFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	stints, err := NewStintService(s.BaseService).GetStintsBySession(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	result := &SessionLongRuns{
+		SessionKey: sessionKey,
+		Options:    opts,
+	}
+	for driverNum, laps := range lapHistory {
+		runs := detectLongRuns(laps, stints[driverNum], opts)
+		if len(runs) == 0 {
+			continue
+		}
+		for i := range runs {
+			runs[i].SessionKey = sessionKey
+		}
+		dl := DriverLongRuns{DriverNumber: driverNum, Runs: runs}
+		if d, ok := drivers[driverNum]; ok {
+			dl.NameAcronym = d.NameAcronym
+			dl.TeamName = d.Team
+			dl.Color = d.Color
+		}
+		dl.Compounds = summariseLongRuns(runs)
+		result.Drivers = append(result.Drivers, dl)
+	}
+	sortDriverLongRuns(result.Drivers)
+
+	return result, nil
+}
+
+// GetMeetingLongRuns 合併大獎賽週末所有練習賽的長距離模擬
+func (s *LongRunService) GetMeetingLongRuns(ctx context.Context, meetingKey int, opts LongRunOptions) (*MeetingLongRuns, error) {
+	sessions, err := fetchRecords[Session](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetSessionByMeeting(ctx, meetingKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].DateStart < sessions[j].DateStart })
+
+	result := &MeetingLongRuns{MeetingKey: meetingKey, Options: opts}
+	byDriver := make(map[int]*DriverLongRuns)
+	for _, sess := range sessions {
+		if !strings.EqualFold(sess.SessionType, "Practice") {
+			continue
+		}
+		sessionRuns, err := s.GetSessionLongRuns(ctx, sess.SessionKey, opts)
+		if err != nil {
+			s.Logger.Warn("Failed to analyse practice session", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+			continue
+		}
+		result.Sessions = append(result.Sessions, sess.SessionName)
+
+		for _, d := range sessionRuns.Drivers {
+			acc, ok := byDriver[d.DriverNumber]
+			if !ok {
+				acc = &DriverLongRuns{
+					DriverNumber: d.DriverNumber,
+					NameAcronym:  d.NameAcronym,
+					TeamName:     d.TeamName,
+					Color:        d.Color,
+				}
+				byDriver[d.DriverNumber] = acc
+			}
+			acc.Runs = append(acc.Runs, d.Runs...)
+		}
+	}
+
+	for _, d := range byDriver {
+		d.Compounds = summariseLongRuns(d.Runs)
+		result.Drivers = append(result.Drivers, *d)
+	}
+	sortDriverLongRuns(result.Drivers)
+
+	return result, nil
+}
+
+// sortDriverLongRuns 依最長一段長距離的平均圈速排序，較快者在前
+func sortDriverLongRuns(drivers []DriverLongRuns) {
+	best := func(d DriverLongRuns) float64 {
+		longest := d.Runs[0]
+		for _, r := range d.Runs[1:] {
+			if r.Laps > longest.Laps {
+				longest = r
+			}
+		}
+		return longest.AveragePace
+	}
+	sort.Slice(drivers, func(i, j int) bool { return best(drivers[i]) < best(drivers[j]) })
+}
+
+// =======================
+// 長距離偵測
+// =======================
+
+// detectLongRuns 在每個 stint 內找出連續的代表圈；中間少量慢圈 (塞車) 會略過但不中斷
+func detectLongRuns(laps []LapRecord, stints []StintRecord, opts LongRunOptions) []LongRun {
+	var runs []LongRun
+	for _, st := range stints {
+		var stintLaps []LapRecord
+		best := 0.0
+		for _, lap := range laps {
+			// 出站圈與進站圈不列入
+			if lap.LapNumber <= st.LapStart || (st.LapEnd > 0 && lap.LapNumber >= st.LapEnd) {
+				continue
+			}
+			if lap.IsPitOutLap || lap.LapDuration <= 0 {
+				continue
+			}
+			stintLaps = append(stintLaps, lap)
+			if best == 0 || lap.LapDuration < best {
+				best = lap.LapDuration
+			}
+		}
+		if len(stintLaps) < opts.MinLaps {
+			continue
+		}
+
+		limit := best * opts.RepresentativeThreshold
+		var current []LapRecord
+		slow := 0
+		lastSlow := 0 // 本段最近一次容許的慢圈
+		flush := func() {
+			if len(current) >= opts.MinLaps {
+				// 代表圈比例過低表示段中夾雜太多慢圈，不是穩定的長距離
+				span := current[len(current)-1].LapNumber - current[0].LapNumber + 1
+				if float64(len(current)) >= opts.MinRepresentativeShare*float64(span) {
+					runs = append(runs, buildLongRun(current, st, opts))
+				}
+			}
+			current = nil
+			slow = 0
+			lastSlow = 0
+		}
+
+		for _, lap := range stintLaps {
+			// 圈數不連續 (例如中途回 pit 未換胎) 視為中斷
+			if len(current) > 0 && lap.LapNumber-current[len(current)-1].LapNumber > opts.MaxSlowLaps+1 {
+				flush()
+			}
+			if lap.LapDuration <= limit {
+				current = append(current, lap)
+				slow = 0
+				continue
+			}
+			slow++
+			// 快慢圈交替 (推圈 / 冷卻圈) 是排位模擬而非長距離
+			alternating := lastSlow > 0 && lap.LapNumber-lastSlow <= 2
+			if slow > opts.MaxSlowLaps || alternating {
+				flush()
+				continue
+			}
+			if len(current) > 0 {
+				lastSlow = lap.LapNumber
+			}
+		}
+		flush()
+	}
+	return runs
+}
+
+func buildLongRun(laps []LapRecord, st StintRecord, opts LongRunOptions) LongRun {
+	first := laps[0].LapNumber
+	raw := make([]float64, len(laps))
+	corrected := make([]float64, len(laps))
+	ages := make([]float64, len(laps))
+	for i, lap := range laps {
+		raw[i] = lap.LapDuration
+		// 油量隨圈數減少，修正回該段開始時的油量
+		corrected[i] = lap.LapDuration + opts.FuelCorrection*float64(lap.LapNumber-first)
+		ages[i] = float64(st.TyreAgeAtStart + lap.LapNumber - st.LapStart)
+	}
+
+	run := LongRun{
+		StintNumber:    st.StintNumber,
+		Compound:       strings.ToUpper(st.Compound),
+		LapStart:       first,
+		LapEnd:         laps[len(laps)-1].LapNumber,
+		Laps:           len(laps),
+		TyreAgeAtStart: int(ages[0]),
+		AveragePace:    mean(raw),
+		MedianPace:     median(raw),
+		CorrectedPace:  mean(corrected),
+		LapTimes:       raw,
+	}
+	if slope, _, ok := linearFit(ages, corrected); ok {
+		run.Degradation = slope
+	}
+	return run
+}
+
+// summariseLongRuns 依配方合併所有長距離，以圈數加權
+func summariseLongRuns(runs []LongRun) []LongRunCompound {
+	index := make(map[string]*LongRunCompound)
+	var order []string
+	for _, r := range runs {
+		c, ok := index[r.Compound]
+		if !ok {
+			c = &LongRunCompound{Compound: r.Compound}
+			index[r.Compound] = c
+			order = append(order, r.Compound)
+		}
+		w := float64(r.Laps)
+		total := float64(c.Laps) + w
+		c.AveragePace = (c.AveragePace*float64(c.Laps) + r.AveragePace*w) / total
+		c.Degradation = (c.Degradation*float64(c.Laps) + r.Degradation*w) / total
+		c.Laps += r.Laps
+		c.Runs++
+	}
+
+	summary := make([]LongRunCompound, 0, len(order))
+	for _, compound := range order {
+		summary = append(summary, *index[compound])
+	}
+	return summary
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 中立化類型
+const (
+	NeutralisationSC  = "SC"
+	NeutralisationVSC = "VSC"
+	NeutralisationRed = "RED"
+)
+
+// minNeutralisedOverlap 圈與中立化期間至少重疊此時間才標記，避免恢復比賽時剛過線的圈被誤標
+const minNeutralisedOverlap = 2 * time.Second
+
+// LapFilter 各分析服務共用的圈過濾設定
+type LapFilter struct {
+	ExcludeNeutralised bool `json:"exclude_neutralised"` // 排除 SC / VSC / 紅旗圈
+}
+
+type NeutralisationService struct {
+	*BaseService
+}
+
+func NewNeutralisationService(base *BaseService) *NeutralisationService {
+	return &NeutralisationService{BaseService: base}
+}
+
+// =======================
+// 主入口: 中立化期間與進站得失
+// =======================
+
+// GetSessionNeutralisations 列出所有 SC、VSC 與紅旗期間，並計算期間內進站車手的名次得失
+func (s *NeutralisationService) GetSessionNeutralisations(ctx context.Context, sessionKey int) (*SessionNeutralisations, error) {
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	periods := ExtractNeutralisations(ParseRaceControlEvents(records))
+
+	result := &SessionNeutralisations{
+		SessionKey:      sessionKey,
+		Periods:         periods,
+		NeutralisedLaps: neutralisedLapNumbers(periods),
+	}
+	if len(periods) == 0 {
+		return result, nil
+	}
+
+	pits, err := fetchRecords[PitRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetPitBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch pit stops", zap.Int("session_key", sessionKey), zap.Error(err))
+		return result, nil
+	}
+	positions, err := NewPositionService(s.BaseService).GetPositionHistory(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch positions", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	for i := range result.Periods {
+		result.Periods[i].PitStops = neutralisedPitStops(result.Periods[i], pits, positions, drivers)
+	}
+	return result, nil
+}
+
+// =======================
+// 期間擷取
+// =======================
+
+// ExtractNeutralisations 由解析後的 race control 事件找出中立化期間。
+// SC / VSC 在結束訊息後的第一面全場綠旗 (或 TRACK CLEAR) 結束，沒有綠旗時以結束訊息為準；
+// 紅旗在恢復比賽、出站口開啟、綠旗或改以 SC 重新起跑時結束。
+func ExtractNeutralisations(events []RaceControlEvent) []NeutralisationPeriod {
+	var periods []NeutralisationPeriod
+	var open *NeutralisationPeriod
+	var ending *RaceControlEvent
+	lastLap := 0
+
+	closeAt := func(at time.Time, lap int) {
+		end := at
+		open.End = &end
+		open.EndLap = max(lap, open.StartLap)
+		periods = append(periods, *open)
+		open, ending = nil, nil
+	}
+	start := func(kind string, ev RaceControlEvent) {
+		switch {
+		case open == nil:
+		case open.Type == kind:
+			return
+		case ending != nil:
+			closeAt(ending.UTC, ending.Lap)
+		default:
+			closeAt(ev.UTC, ev.Lap)
+		}
+		open = &NeutralisationPeriod{Type: kind, Start: ev.UTC, StartLap: max(ev.Lap, 1)}
+	}
+
+	for _, ev := range events {
+		lastLap = max(lastLap, ev.Lap)
+		greenTrack := strings.EqualFold(ev.Scope, "Track") &&
+			(strings.EqualFold(ev.Flag, "GREEN") || strings.EqualFold(ev.Flag, "CLEAR"))
+
+		switch {
+		case ev.Type == EventSafetyCarDeployed:
+			start(NeutralisationSC, ev)
+		case ev.Type == EventVSCDeployed:
+			// SC 期間的 VSC 訊息忽略；VSC 升級為 SC 由上一個 case 處理
+			if open == nil || open.Type != NeutralisationSC {
+				start(NeutralisationVSC, ev)
+			}
+		case ev.Type == EventSessionStopped && strings.EqualFold(ev.Flag, "RED"):
+			start(NeutralisationRed, ev)
+		case open == nil:
+			continue
+		case ev.Type == EventSafetyCarEnding && open.Type == NeutralisationSC,
+			ev.Type == EventVSCEnding && open.Type == NeutralisationVSC:
+			e := ev
+			ending = &e
+		case open.Type == NeutralisationRed &&
+			(ev.Type == EventSessionResume || ev.Type == EventSessionStart || greenTrack):
+			closeAt(ev.UTC, ev.Lap)
+		case ending != nil && greenTrack:
+			// 綠旗通常在恢復比賽那一圈的起點發出，結束圈仍以結束訊息的圈數為準
+			closeAt(ev.UTC, ending.Lap)
+		case ending != nil && ev.Type == EventChequeredFlag:
+			closeAt(ending.UTC, ending.Lap)
+		}
+	}
+
+	if open != nil {
+		if ending != nil {
+			closeAt(ending.UTC, ending.Lap)
+		} else {
+			open.EndLap = max(lastLap, open.StartLap)
+			periods = append(periods, *open)
+		}
+	}
+	return periods
+}
+
+// neutralisedLapNumbers 以領先者圈數列出所有中立化圈
+func neutralisedLapNumbers(periods []NeutralisationPeriod) []int {
+	set := make(map[int]bool)
+	for _, p := range periods {
+		for lap := p.StartLap; lap <= p.EndLap; lap++ {
+			set[lap] = true
+		}
+	}
+	return sortedLapSet(set)
+}
+
+func sortedLapSet(set map[int]bool) []int {
+	laps := make([]int, 0, len(set))
+	for lap := range set {
+		laps = append(laps, lap)
+	}
+	sort.Ints(laps)
+	return laps
+}
+
+// =======================
+// 圈標記與過濾
+// =======================
+
+// markNeutralisedLaps 以時間重疊標記圈；沒有時間資料的圈退回以圈數判斷
+func markNeutralisedLaps(lapHistory map[int][]LapRecord, periods []NeutralisationPeriod) {
+	for _, laps := range lapHistory {
+		for i := range laps {
+			lap := &laps[i]
+			start, end, timed := lapTimeRange(laps, i)
+			for _, p := range periods {
+				var hit bool
+				if timed {
+					from := maxTime(start, p.Start)
+					to := end
+					if p.End != nil && p.End.Before(to) {
+						to = *p.End
+					}
+					hit = to.Sub(from) >= minNeutralisedOverlap
+				} else {
+					hit = lap.LapNumber >= p.StartLap && lap.LapNumber <= p.EndLap
+				}
+				if hit {
+					lap.Neutralised = p.Type
+					break
+				}
+			}
+		}
+	}
+}
+
+// lapTimeRange 圈的起訖時間：下一圈的開始，沒有下一圈時以圈速推算
+func lapTimeRange(laps []LapRecord, i int) (time.Time, time.Time, bool) {
+	lap := laps[i]
+	if lap.DateStart.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	if i+1 < len(laps) && laps[i+1].LapNumber == lap.LapNumber+1 && !laps[i+1].DateStart.IsZero() {
+		return lap.DateStart, laps[i+1].DateStart, true
+	}
+	if lap.LapDuration <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	return lap.DateStart, lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second))), true
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// =======================
+// 中立化期間進站
+// =======================
+
+// neutralisedPitStops 期間內進站的車手，名次比較期間開始與結束時的排位
+func neutralisedPitStops(p NeutralisationPeriod, pits []PitRecord, positions map[int][]PositionRecord, drivers map[int]Driver) []NeutralisedPitStop {
+	var stops []NeutralisedPitStop
+	seen := make(map[int]bool)
+	for _, pit := range pits {
+		inside := !pit.Date.Before(p.Start) && (p.End == nil || pit.Date.Before(*p.End))
+		if pit.Date.IsZero() {
+			inside = pit.LapNumber >= p.StartLap && pit.LapNumber <= p.EndLap
+		}
+		if !inside || seen[pit.DriverNumber] {
+			continue
+		}
+		seen[pit.DriverNumber] = true
+
+		stop := NeutralisedPitStop{
+			DriverNumber: pit.DriverNumber,
+			LapNumber:    pit.LapNumber,
+			PitDuration:  pit.PitDuration,
+		}
+		if d, ok := drivers[pit.DriverNumber]; ok {
+			stop.NameAcronym = d.NameAcronym
+			stop.TeamName = d.Team
+			stop.Color = d.Color
+		}
+
+		history := positions[pit.DriverNumber]
+		stop.PositionBefore = positionAt(history, p.Start)
+		if p.End != nil {
+			stop.PositionAfter = positionAt(history, *p.End)
+		} else if len(history) > 0 {
+			stop.PositionAfter = history[len(history)-1].Position
+		}
+		if stop.PositionBefore > 0 && stop.PositionAfter > 0 {
+			stop.Gained = stop.PositionBefore - stop.PositionAfter
+		}
+		stops = append(stops, stop)
+	}
+
+	sort.Slice(stops, func(i, j int) bool {
+		if stops[i].Gained != stops[j].Gained {
+			return stops[i].Gained > stops[j].Gained
+		}
+		return stops[i].DriverNumber < stops[j].DriverNumber
+	})
+	return stops
+}
+
+// positionAt 取時間 t 當下 (含) 最後一筆名次，沒有資料時為 0；history 需依時間排序
+func positionAt(history []PositionRecord, t time.Time) int {
+	i := sort.Search(len(history), func(i int) bool { return history[i].Date.After(t) })
+	if i == 0 {
+		return 0
+	}
+	return history[i-1].Position
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// 事件處理狀態
+const (
+	IncidentNoted              = "noted"
+	IncidentUnderInvestigation = "under_investigation"
+	IncidentDecided            = "decided"
+)
+
+// DecisionNoFurtherAction 不處罰；其餘裁決沿用 Penalty* 類型
+const DecisionNoFurtherAction = "no_further_action"
+
+// 超級駕照罰分與申誡的禁賽門檻
+const (
+	SuperlicenceBanPoints = 12 // 12 個月內累計 12 分禁賽一場
+	ReprimandLimit        = 5  // 同一賽季第 5 次申誡處以退後 10 位
+	penaltyRiskMargin     = 4  // 距離禁賽不超過此分數視為高風險
+)
+
+// 訊息中事件描述的結尾；之前的文字 (去掉 FIA STEWARDS 前綴) 用來串接同一事件
+var incidentSubjectEndings = []string{" NOTED", " UNDER INVESTIGATION", " WILL BE INVESTIGATED", " REVIEWED", " NO FURTHER", " NO INVESTIGATION"}
+
+// PenaltyPointsRule 罪名 (依 reason 關鍵字比對) 對應的罰分
+type PenaltyPointsRule struct {
+	Offence string `json:"offence"`
+	Points  int    `json:"points"`
+}
+
+// PenaltyTrackerOptions 罰分對照表與禁賽門檻；規則依序比對，第一條符合者生效
+type PenaltyTrackerOptions struct {
+	PointsRules    []PenaltyPointsRule `json:"points_rules"`
+	BanPoints      int                 `json:"ban_points"`
+	ReprimandLimit int                 `json:"reprimand_limit"`
+}
+
+// DefaultPenaltyTrackerOptions 近年幹事常見的罰分：碰撞、逼出賽道、黃旗 / SC 違規 2 分，其他駕駛違規 1 分
+func DefaultPenaltyTrackerOptions() PenaltyTrackerOptions {
+	return PenaltyTrackerOptions{
+		PointsRules: []PenaltyPointsRule{
+			{Offence: "CAUSING A COLLISION", Points: 2},
+			{Offence: "FORCING ANOTHER DRIVER OFF THE TRACK", Points: 2},
+			{Offence: "DANGEROUS DRIVING", Points: 2},
+			{Offence: "YELLOW FLAG", Points: 2},
+			{Offence: "SAFETY CAR", Points: 2},
+			{Offence: "RED FLAG", Points: 2},
+			{Offence: "LEAVING THE TRACK AND GAINING", Points: 1},
+			{Offence: "IMPEDING", Points: 1},
+			{Offence: "MORE THAN ONE CHANGE OF DIRECTION", Points: 1},
+			{Offence: "UNSAFE", Points: 1},
+		},
+		BanPoints:      SuperlicenceBanPoints,
+		ReprimandLimit: ReprimandLimit,
+	}
+}
+
+// pointsFor 罰分只適用於實際處罰；申誡、不處罰與取消資格不計分
+func (o PenaltyTrackerOptions) pointsFor(inc Incident) int {
+	if inc.Penalty == nil {
+		return 0
+	}
+	switch inc.Penalty.Type {
+	case PenaltyReprimand, PenaltyDisqualified:
+		return 0
+	}
+	reason := strings.ToUpper(inc.Reason)
+	for _, rule := range o.PointsRules {
+		if rule.Offence != "" && strings.Contains(reason, strings.ToUpper(rule.Offence)) {
+			return rule.Points
+		}
+	}
+	return 0
+}
+
+type PenaltyTrackerService struct {
+	*BaseService
+}
+
+func NewPenaltyTrackerService(base *BaseService) *PenaltyTrackerService {
+	return &PenaltyTrackerService{BaseService: base}
+}
+
+// =======================
+// 主入口: 單一 session / 整個賽季
+// =======================
+
+// GetSessionIncidents 追蹤單一 session 每個事件從注意、調查到裁決的過程
+func (s *PenaltyTrackerService) GetSessionIncidents(ctx context.Context, sessionKey int, opts PenaltyTrackerOptions) (*SessionIncidents, error) {
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	incidents, _ := trackIncidents(Session{SessionKey: sessionKey}, ParseRaceControlEvents(records))
+	for i := range incidents {
+		incidents[i].PenaltyPoints = opts.pointsFor(incidents[i])
+	}
+	return &SessionIncidents{SessionKey: sessionKey, Incidents: incidents}, nil
+}
+
+// GetSeasonPenalties 彙整賽季所有 session 的事件，累計每位車手的處罰、申誡與超級駕照罰分。
+// 罰分只計算本賽季；實際禁賽以 12 個月滾動計算，跨季的分數需另外加總。
+func (s *PenaltyTrackerService) GetSeasonPenalties(ctx context.Context, year int, opts PenaltyTrackerOptions) (*SeasonPenalties, error) {
+	sessions, err := s.getPastSessions(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	type sessionIncidents struct {
+		incidents []Incident
+		acronyms  map[int]string
+	}
+	perSession := make([]*sessionIncidents, len(sessions))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func(i int, sess Session) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if s.throttle(ctx) != nil {
+				return
+			}
+			records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+				return s.DS.GetRaceControlBySession(ctx, sess.SessionKey)
+			})
+			if err != nil {
+				s.Logger.Warn("Failed to fetch race control for penalties", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+				return
+			}
+			incidents, acronyms := trackIncidents(sess, ParseRaceControlEvents(records))
+			perSession[i] = &sessionIncidents{incidents: incidents, acronyms: acronyms}
+		}(i, sess)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SeasonPenalties{Year: year, Options: opts}
+	drivers := make(map[int]*DriverPenaltyRecord)
+	record := func(num int) *DriverPenaltyRecord {
+		dr, ok := drivers[num]
+		if !ok {
+			dr = &DriverPenaltyRecord{DriverNumber: num}
+			drivers[num] = dr
+		}
+		return dr
+	}
+
+	for _, si := range perSession {
+		if si == nil {
+			continue
+		}
+		result.Sessions++
+		for num, acronym := range si.acronyms {
+			record(num).NameAcronym = acronym
+		}
+
+		for _, inc := range si.incidents {
+			inc.PenaltyPoints = opts.pointsFor(inc)
+			result.Incidents = append(result.Incidents, inc)
+
+			for _, num := range inc.Drivers {
+				dr := record(num)
+				dr.Incidents++
+				if inc.InvestigatedAt != nil {
+					dr.Investigations++
+				}
+			}
+			if inc.PenalisedDriver == nil {
+				continue
+			}
+			dr := record(*inc.PenalisedDriver)
+			switch inc.Penalty.Type {
+			case PenaltyReprimand:
+				dr.Reprimands++
+			default:
+				dr.Penalties++
+				dr.TimePenaltySeconds += inc.Penalty.Seconds
+				dr.GridPlaces += inc.Penalty.GridPlaces
+			}
+			dr.PenaltyPoints += inc.PenaltyPoints
+		}
+	}
+
+	for _, dr := range drivers {
+		dr.PointsToBan = max(opts.BanPoints-dr.PenaltyPoints, 0)
+		dr.ReprimandsToPenalty = max(opts.ReprimandLimit-dr.Reprimands, 0)
+		dr.AtRisk = dr.PenaltyPoints > 0 && dr.PointsToBan <= penaltyRiskMargin ||
+			dr.Reprimands > 0 && dr.ReprimandsToPenalty <= 1
+		result.Drivers = append(result.Drivers, *dr)
+	}
+	sort.Slice(result.Drivers, func(i, j int) bool {
+		a, b := result.Drivers[i], result.Drivers[j]
+		if a.PenaltyPoints != b.PenaltyPoints {
+			return a.PenaltyPoints > b.PenaltyPoints
+		}
+		if a.Reprimands != b.Reprimands {
+			return a.Reprimands > b.Reprimands
+		}
+		return a.DriverNumber < b.DriverNumber
+	})
+
+	return result, nil
+}
+
+// =======================
+// 事件串接
+// =======================
+
+// trackIncidents 依訊息中的事件描述串接同一事件；處罰訊息沒有事件描述，
+// 以被罰車手與原因對應尚未裁決的事件，找不到時另立新事件。同時回傳訊息中出現的車手縮寫。
+func trackIncidents(sess Session, events []RaceControlEvent) ([]Incident, map[int]string) {
+	var incidents []Incident
+	acronyms := make(map[int]string)
+
+	find := func(match func(inc *Incident) bool) *Incident {
+		for i := len(incidents) - 1; i >= 0; i-- {
+			if match(&incidents[i]) {
+				return &incidents[i]
+			}
+		}
+		return nil
+	}
+	open := func(ev RaceControlEvent, subject string) *Incident {
+		incidents = append(incidents, Incident{
+			ID:          fmt.Sprintf("%d-%d", sess.SessionKey, len(incidents)+1),
+			SessionKey:  sess.SessionKey,
+			MeetingKey:  ev.MeetingKey,
+			SessionName: sess.SessionName,
+			Location:    sess.Location,
+			Lap:         ev.Lap,
+			Turn:        ev.Turn,
+			Drivers:     ev.Drivers,
+			Description: subject,
+			Reason:      ev.Reason,
+			Status:      IncidentNoted,
+		})
+		return &incidents[len(incidents)-1]
+	}
+
+	for _, ev := range events {
+		msg := strings.ToUpper(ev.Message)
+		for _, m := range rcCarPattern.FindAllStringSubmatch(msg, -1) {
+			if n, err := strconv.Atoi(m[1]); err == nil {
+				acronyms[n] = m[2]
+			}
+		}
+
+		subject := incidentSubject(msg)
+		sameSubject := func(inc *Incident) bool {
+			return inc.Status != IncidentDecided && subject != "" && inc.Description == subject
+		}
+		at := ev.UTC
+
+		switch ev.Type {
+		case EventIncidentNoted:
+			if inc := find(sameSubject); inc == nil {
+				inc = open(ev, subject)
+				inc.NotedAt = &at
+			}
+		case EventInvestigation:
+			inc := find(sameSubject)
+			if inc == nil {
+				inc = open(ev, subject)
+			}
+			inc.Status = IncidentUnderInvestigation
+			inc.InvestigatedAt = &at
+			if inc.Reason == "" {
+				inc.Reason = ev.Reason
+			}
+		case EventNoFurtherAction:
+			inc := find(sameSubject)
+			if inc == nil {
+				inc = find(func(inc *Incident) bool {
+					return inc.Status != IncidentDecided && len(ev.Drivers) > 0 && sameDrivers(inc.Drivers, ev.Drivers)
+				})
+			}
+			if inc == nil {
+				inc = open(ev, subject)
+			}
+			inc.Status = IncidentDecided
+			inc.Decision = DecisionNoFurtherAction
+			inc.DecidedAt = &at
+		case EventPenalty:
+			if ev.DriverNumber == nil || ev.Penalty == nil {
+				continue
+			}
+			driver := *ev.DriverNumber
+			involved := func(inc *Incident) bool {
+				return inc.Status != IncidentDecided && slices.Contains(inc.Drivers, driver)
+			}
+			inc := find(func(inc *Incident) bool { return involved(inc) && inc.Reason == ev.Reason })
+			if inc == nil {
+				inc = find(involved)
+			}
+			if inc == nil {
+				inc = open(ev, "")
+			}
+			inc.Status = IncidentDecided
+			inc.Decision = ev.Penalty.Type
+			inc.Penalty = ev.Penalty
+			inc.PenalisedDriver = &driver
+			inc.DecidedAt = &at
+			if inc.Reason == "" {
+				inc.Reason = ev.Reason
+			}
+		}
+	}
+	return incidents, acronyms
+}
+
+// incidentSubject 取出訊息中的事件描述，例如 "TURN 4 INCIDENT INVOLVING CARS 1 (VER) AND 44 (HAM)"
+func incidentSubject(msg string) string {
+	msg = strings.TrimPrefix(msg, "FIA STEWARDS: ")
+	for _, ending := range incidentSubjectEndings {
+		if i := strings.Index(msg, ending); i > 0 {
+			return strings.TrimSpace(msg[:i])
+		}
+	}
+	return ""
+}
+
+func sameDrivers(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, n := range b {
+		if !slices.Contains(a, n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// 進站攻防類型：後車先進站為 undercut，後車晚進站為 overcut
+const (
+	PitAttemptUndercut = "undercut"
+	PitAttemptOvercut  = "overcut"
+)
+
+// 預設門檻：進站前與前車差距 3 秒內，兩車進站相隔不超過 5 圈
+const (
+	DefaultPitAttemptGap       = 3.0
+	DefaultPitAttemptLapsApart = 5
+)
+
+// PitStrategyOptions undercut / overcut 判定門檻
+type PitStrategyOptions struct {
+	MaxGap       float64 `json:"max_gap"`        // 第一次進站前一圈，後車與前車的最大差距 (秒)
+	MaxLapsApart int     `json:"max_laps_apart"` // 兩車進站相隔的最大圈數
+}
+
+func DefaultPitStrategyOptions() PitStrategyOptions {
+	return PitStrategyOptions{MaxGap: DefaultPitAttemptGap, MaxLapsApart: DefaultPitAttemptLapsApart}
+}
+
+type PitStrategyService struct {
+	*BaseService
+}
+
+func NewPitStrategyService(base *BaseService) *PitStrategyService {
+	return &PitStrategyService{BaseService: base}
+}
+
+// =======================
+// 主入口: 單場 / 整個賽季
+// =======================
+
+// GetSessionPitStrategy 找出正賽中所有直接競爭車手之間的 undercut / overcut 嘗試，並彙整各車隊的成效
+func (s *PitStrategyService) GetSessionPitStrategy(ctx context.Context, sessionKey int, opts PitStrategyOptions) (*SessionPitStrategy, error) {
+	attempts, err := s.loadPitAttempts(ctx, sessionKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionPitStrategy{
+		SessionKey: sessionKey,
+		Options:    opts,
+		Attempts:   attempts,
+		Teams:      aggregatePitAttempts(attempts),
+	}, nil
+}
+
+// GetSeasonPitStrategy 彙整賽季每場正賽的 undercut / overcut，依車隊統計成功率與時間得失
+func (s *PitStrategyService) GetSeasonPitStrategy(ctx context.Context, year int, opts PitStrategyOptions) (*SeasonPitStrategy, error) {
+	sessions, err := s.getPastSessions(ctx, year, "Race")
+	if err != nil {
+		return nil, err
+	}
+
+	perSession := make([][]PitAttempt, len(sessions))
+	loaded := make([]bool, len(sessions))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func(i int, sess Session) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			attempts, err := s.loadPitAttempts(ctx, sess.SessionKey, opts)
+			if err != nil {
+				s.Logger.Warn("Failed to load pit strategy", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+				return
+			}
+			perSession[i] = attempts
+			loaded[i] = true
+		}(i, sess)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SeasonPitStrategy{Year: year, Options: opts, Attempts: []PitAttempt{}}
+	for i, attempts := range perSession {
+		if !loaded[i] {
+			continue
+		}
+		result.Races++
+		result.Attempts = append(result.Attempts, attempts...)
+	}
+	result.Teams = aggregatePitAttempts(result.Attempts)
+	return result, nil
+}
+
+// loadPitAttempts 圈資料與進站記錄為必要資料；名次、輪胎與車手名單缺少時只略過對應欄位
+func (s *PitStrategyService) loadPitAttempts(ctx context.Context, sessionKey int, opts PitStrategyOptions) ([]PitAttempt, error) {
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	lapHistory, _, err := NewLapService(s.BaseService).GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	pits, err := fetchRecords[PitRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetPitBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	positions, err := NewPositionService(s.BaseService).GetPositionHistory(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch positions", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	stints, err := NewStintService(s.BaseService).GetStintsBySession(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch stints", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	attempts := detectPitAttempts(lapHistory, pits, positions, stints, drivers, opts)
+	for i := range attempts {
+		attempts[i].SessionKey = sessionKey
+	}
+	return attempts, nil
+}
+
+// =======================
+// 偵測
+// =======================
+
+// detectPitAttempts 第一位進站車手在進站前一圈與正前方或正後方的車差距在門檻內，且對手在門檻圈數內進站，
+// 即為一次嘗試。後車先進站為 undercut，前車先進站而後車留在場上為 overcut。
+// 時間得失以兩車都完成出站圈後的差距比較進站前的差距；SC / VSC / 紅旗圈進站不列入。
+func detectPitAttempts(lapHistory map[int][]LapRecord, pits []PitRecord, positions map[int][]PositionRecord, stints map[int][]StintRecord, drivers map[int]Driver, opts PitStrategyOptions) []PitAttempt {
+	tl := buildRaceTimeline(lapHistory)
+
+	stops := make(map[int][]PitRecord)
+	for _, pit := range pits {
+		if pit.LapNumber > 1 {
+			stops[pit.DriverNumber] = append(stops[pit.DriverNumber], pit)
+		}
+	}
+	for num := range stops {
+		sort.Slice(stops[num], func(i, j int) bool { return stops[num][i].LapNumber < stops[num][j].LapNumber })
+	}
+
+	neutralised := func(driver, lap int) bool {
+		laps := lapHistory[driver]
+		i := slices.IndexFunc(laps, func(l LapRecord) bool { return l.LapNumber == lap })
+		return i >= 0 && laps[i].Neutralised != ""
+	}
+	// nextStop 對手在 (after, after+MaxLapsApart] 圈內的第一次進站
+	nextStop := func(driver, after int) (PitRecord, bool) {
+		for _, pit := range stops[driver] {
+			if pit.LapNumber > after && pit.LapNumber <= after+opts.MaxLapsApart {
+				return pit, true
+			}
+		}
+		return PitRecord{}, false
+	}
+
+	attempts := []PitAttempt{}
+	seen := make(map[[3]int]bool)
+	for first, firstStops := range stops {
+		for _, fs := range firstStops {
+			before := fs.LapNumber - 1
+			if before < 1 || neutralised(first, fs.LapNumber) {
+				continue
+			}
+			crossings := tl.laps[before]
+			idx := slices.IndexFunc(crossings, func(c lapCrossing) bool { return c.driver == first })
+			if idx < 0 {
+				continue
+			}
+
+			// 正前方 (本車為後車 → undercut) 與正後方 (對手為後車 → overcut)
+			type candidate struct{ rival, attacker, defender int }
+			var candidates []candidate
+			if idx > 0 && crossings[idx].gap <= opts.MaxGap {
+				candidates = append(candidates, candidate{rival: crossings[idx-1].driver, attacker: first, defender: crossings[idx-1].driver})
+			}
+			if idx+1 < len(crossings) && crossings[idx+1].gap <= opts.MaxGap {
+				candidates = append(candidates, candidate{rival: crossings[idx+1].driver, attacker: crossings[idx+1].driver, defender: first})
+			}
+
+			for _, c := range candidates {
+				ss, ok := nextStop(c.rival, fs.LapNumber)
+				if !ok || neutralised(c.rival, ss.LapNumber) {
+					continue
+				}
+				key := [3]int{min(first, c.rival), max(first, c.rival), fs.LapNumber}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				attackerPit, defenderPit := fs, ss
+				kind := PitAttemptUndercut
+				if c.attacker != first {
+					attackerPit, defenderPit = ss, fs
+					kind = PitAttemptOvercut
+				}
+				attempt, ok := evaluatePitAttempt(tl, kind, c.attacker, c.defender, attackerPit, defenderPit, fs.LapNumber, ss.LapNumber)
+				if !ok {
+					continue
+				}
+
+				if d, ok := drivers[c.attacker]; ok {
+					attempt.AttackerTeam = d.Team
+				}
+				if d, ok := drivers[c.defender]; ok {
+					attempt.DefenderTeam = d.Team
+				}
+				attempt.Teammates = attempt.AttackerTeam != "" && attempt.AttackerTeam == attempt.DefenderTeam
+				if st, ok := stintForLap(stints[c.attacker], attackerPit.LapNumber+1); ok {
+					attempt.AttackerCompound = st.Compound
+				}
+				if st, ok := stintForLap(stints[c.defender], defenderPit.LapNumber+1); ok {
+					attempt.DefenderCompound = st.Compound
+				}
+
+				history := positions[c.attacker]
+				attempt.PositionBefore = positionAt(history, tl.endOf(c.attacker, before))
+				attempt.PositionAfter = positionAt(history, tl.endOf(c.attacker, ss.LapNumber+1))
+				if attempt.PositionBefore > 0 && attempt.PositionAfter > 0 {
+					attempt.PositionsGained = attempt.PositionBefore - attempt.PositionAfter
+				}
+				attempts = append(attempts, attempt)
+			}
+		}
+	}
+
+	sort.Slice(attempts, func(i, j int) bool {
+		a, b := attempts[i], attempts[j]
+		if a.FirstStopLap != b.FirstStopLap {
+			return a.FirstStopLap < b.FirstStopLap
+		}
+		return a.Attacker < b.Attacker
+	})
+	return attempts
+}
+
+// evaluatePitAttempt 比較第一次進站前一圈與第二位車手出站圈結束時的差距 (後車減前車，正數為落後)。
+// 時間得失拆成第一位進站車手的出站圈、第二位進站車手的進站圈與其餘圈 (含進站與新舊胎交錯的圈)，
+// 各項皆為對手圈速減攻方圈速，正數為攻方得利
+func evaluatePitAttempt(tl *raceTimeline, kind string, attacker, defender int, attackerPit, defenderPit PitRecord, firstLap, secondLap int) (PitAttempt, bool) {
+	before, after := firstLap-1, secondLap+1
+	ab, db := tl.endOf(attacker, before), tl.endOf(defender, before)
+	aa, da := tl.endOf(attacker, after), tl.endOf(defender, after)
+	if ab.IsZero() || db.IsZero() || aa.IsZero() || da.IsZero() {
+		return PitAttempt{}, false
+	}
+
+	lapGain := func(lap int) float64 {
+		att := tl.endOf(attacker, lap).Sub(tl.endOf(attacker, lap-1)).Seconds()
+		def := tl.endOf(defender, lap).Sub(tl.endOf(defender, lap-1)).Seconds()
+		return def - att
+	}
+
+	attempt := PitAttempt{
+		Type:                kind,
+		Attacker:            attacker,
+		Defender:            defender,
+		AttackerPitLap:      attackerPit.LapNumber,
+		DefenderPitLap:      defenderPit.LapNumber,
+		AttackerPitDuration: attackerPit.PitDuration,
+		DefenderPitDuration: defenderPit.PitDuration,
+		FirstStopLap:        firstLap,
+		GapBefore:           ab.Sub(db).Seconds(),
+		GapAfter:            aa.Sub(da).Seconds(),
+	}
+	attempt.TimeGained = attempt.GapBefore - attempt.GapAfter
+	attempt.Success = attempt.GapAfter < 0
+	attempt.OutLapGain = lapGain(firstLap + 1)
+	if secondLap > firstLap+1 {
+		attempt.InLapGain = lapGain(secondLap)
+	}
+	attempt.OtherGain = attempt.TimeGained - attempt.OutLapGain - attempt.InLapGain
+	return attempt, true
+}
+
+// =======================
+// 車隊彙整
+// =======================
+
+// aggregatePitAttempts 依車隊統計攻守次數與成功率；同隊車手之間的進站對同一車隊既是進攻也是防守，排除
+func aggregatePitAttempts(attempts []PitAttempt) []TeamPitStrategy {
+	teams := make(map[string]*TeamPitStrategy)
+	team := func(name string) *TeamPitStrategy {
+		t, ok := teams[name]
+		if !ok {
+			t = &TeamPitStrategy{TeamName: name}
+			teams[name] = t
+		}
+		return t
+	}
+
+	for _, a := range attempts {
+		if a.Teammates {
+			continue
+		}
+		if a.AttackerTeam != "" {
+			t := team(a.AttackerTeam)
+			switch a.Type {
+			case PitAttemptUndercut:
+				t.Undercuts++
+				if a.Success {
+					t.UndercutSuccesses++
+				}
+			case PitAttemptOvercut:
+				t.Overcuts++
+				if a.Success {
+					t.OvercutSuccesses++
+				}
+			}
+			t.TimeGained += a.TimeGained
+		}
+		if a.DefenderTeam != "" {
+			t := team(a.DefenderTeam)
+			t.Defences++
+			if !a.Success {
+				t.SuccessfulDefences++
+			}
+		}
+	}
+
+	result := make([]TeamPitStrategy, 0, len(teams))
+	for _, t := range teams {
+		if n := t.Undercuts + t.Overcuts; n > 0 {
+			t.SuccessRate = float64(t.UndercutSuccesses+t.OvercutSuccesses) / float64(n)
+			t.AvgTimeGained = t.TimeGained / float64(n)
+		}
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.SuccessRate != b.SuccessRate {
+			return a.SuccessRate > b.SuccessRate
+		}
+		if na, nb := a.Undercuts+a.Overcuts, b.Undercuts+b.Overcuts; na != nb {
+			return na > nb
+		}
+		return a.TeamName < b.TeamName
+	})
+	return result
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrUnknownPointsSystem 找不到指定的積分制度
+	ErrUnknownPointsSystem = errors.New("unknown points system")
+
+	// ErrInvalidPointsSystem 自訂積分制度不合法
+	ErrInvalidPointsSystem = errors.New("invalid points system")
+)
+
+// PointsSystemCurrent 查詢賽季實際採用的積分制度的別名
+const PointsSystemCurrent = "current"
+
+// PointsSystem 描述一套名次對應積分的規則
+type PointsSystem struct {
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	RacePoints      []float64 `json:"race_points"`       // 正賽第一名起的積分
+	SprintPoints    []float64 `json:"sprint_points"`     // Sprint 第一名起的積分，nil 表示不計分
+	FastestLapPoint float64   `json:"fastest_lap_point"` // 正賽最快圈加分
+	FastestLapTopN  int       `json:"fastest_lap_top_n"` // 最快圈需完賽於前 N 名才加分，0 表示不限
+}
+
+// PointsFor 回傳指定名次的積分，position <= 0 表示未完賽
+func (p *PointsSystem) PointsFor(position int, isSprint, fastestLap bool) float64 {
+	table := p.RacePoints
+	if isSprint {
+		table = p.SprintPoints
+	}
+
+	points := 0.0
+	if position >= 1 && position <= len(table) {
+		points = table[position-1]
+	}
+	if !isSprint && fastestLap && position >= 1 && (p.FastestLapTopN == 0 || position <= p.FastestLapTopN) {
+		points += p.FastestLapPoint
+	}
+	return points
+}
+
+// Table 回傳場次對應的積分表與最快圈加分
+func (p *PointsSystem) Table(isSprint bool) ([]float64, float64) {
+	if isSprint {
+		return p.SprintPoints, 0
+	}
+	return p.RacePoints, p.FastestLapPoint
+}
+
+func (p *PointsSystem) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPointsSystem)
+	}
+	if len(p.RacePoints) == 0 {
+		return fmt.Errorf("%w: race points are required", ErrInvalidPointsSystem)
+	}
+	for _, table := range [][]float64{p.RacePoints, p.SprintPoints} {
+		for i, pts := range table {
+			if pts < 0 {
+				return fmt.Errorf("%w: negative points at position %d", ErrInvalidPointsSystem, i+1)
+			}
+		}
+	}
+	if p.FastestLapPoint < 0 || p.FastestLapTopN < 0 {
+		return fmt.Errorf("%w: negative fastest lap settings", ErrInvalidPointsSystem)
+	}
+	return nil
+}
+
+// =======================
+// 積分制度註冊表
+// =======================
+
+// pointsSystems 內建的歷代積分制度，只讀；自訂制度由 what-if 請求直接帶入，不進註冊表
+var (
+	pointsSystems = map[string]*PointsSystem{
+		"2025": {
+			Name:         "2025",
+			Description:  "25-18-15-12-10-8-6-4-2-1, sprint 8-1, no fastest lap point",
+			RacePoints:   []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+			SprintPoints: []float64{8, 7, 6, 5, 4, 3, 2, 1},
+		},
+		"2022-2024": {
+			Name:            "2022-2024",
+			Description:     "25-18-15-12-10-8-6-4-2-1, sprint 8-1, fastest lap point inside top 10",
+			RacePoints:      []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+			SprintPoints:    []float64{8, 7, 6, 5, 4, 3, 2, 1},
+			FastestLapPoint: 1,
+			FastestLapTopN:  10,
+		},
+		"2021": {
+			Name:            "2021",
+			Description:     "25-18-15-12-10-8-6-4-2-1, sprint 3-2-1, fastest lap point inside top 10",
+			RacePoints:      []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+			SprintPoints:    []float64{3, 2, 1},
+			FastestLapPoint: 1,
+			FastestLapTopN:  10,
+		},
+		"2019-2020": {
+			Name:            "2019-2020",
+			Description:     "25-18-15-12-10-8-6-4-2-1, fastest lap point inside top 10",
+			RacePoints:      []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+			FastestLapPoint: 1,
+			FastestLapTopN:  10,
+		},
+		"2010-2018": {
+			Name:        "2010-2018",
+			Description: "25-18-15-12-10-8-6-4-2-1, no fastest lap point",
+			RacePoints:  []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		},
+		"2003-2009": {
+			Name:        "2003-2009",
+			Description: "10-8-6-5-4-3-2-1",
+			RacePoints:  []float64{10, 8, 6, 5, 4, 3, 2, 1},
+		},
+		"1991-2002": {
+			Name:        "1991-2002",
+			Description: "10-6-4-3-2-1",
+			RacePoints:  []float64{10, 6, 4, 3, 2, 1},
+		},
+	}
+)
+
+// GetPointsSystem 依名稱取得積分制度，"current" 對應 year 賽季實際採用的制度
+func GetPointsSystem(name string, year int) (*PointsSystem, error) {
+	if name == PointsSystemCurrent {
+		return OfficialPointsSystem(year), nil
+	}
+	return lookupPointsSystem(name)
+}
+
+func lookupPointsSystem(name string) (*PointsSystem, error) {
+	ps, ok := pointsSystems[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPointsSystem, name)
+	}
+	return ps, nil
+}
+
+// ListPointsSystems 回傳所有內建的積分制度，依名稱排序
+func ListPointsSystems() []*PointsSystem {
+	list := make([]*PointsSystem, 0, len(pointsSystems))
+	for _, ps := range pointsSystems {
+		list = append(list, ps)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name > list[j].Name })
+	return list
+}
+
+// OfficialPointsSystem 回傳該年度實際採用的積分制度
+func OfficialPointsSystem(year int) *PointsSystem {
+	var name string
+	switch {
+	case year >= 2025:
+		name = "2025"
+	case year >= 2022:
+		name = "2022-2024"
+	case year == 2021:
+		name = "2021"
+	case year >= 2019:
+		name = "2019-2020"
+	case year >= 2010:
+		name = "2010-2018"
+	case year >= 2003:
+		name = "2003-2009"
+	default:
+		name = "1991-2002"
+	}
+
+	ps, _ := lookupPointsSystem(name)
+	return ps
+}
+
+// NewCustomPointsSystem 建立使用者自訂的積分制度 (不會註冊)
+func NewCustomPointsSystem(racePoints, sprintPoints []float64, fastestLapPoint float64) (*PointsSystem, error) {
+	ps := &PointsSystem{
+		Name:            "custom",
+		Description:     "user-defined points table",
+		RacePoints:      racePoints,
+		SprintPoints:    sprintPoints,
+		FastestLapPoint: fastestLapPoint,
+		FastestLapTopN:  10,
+	}
+	if err := ps.validate(); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
@@ -5,12 +5,18 @@ import (
 	"encoding/json"
 	"sort"
 	"time"
+
+	"go.uber.org/zap"
 )
 
-type LapRanking struct {
-	LapNumber int   `json:"lap_number"`
-	Rank      []int `json:"rank"` // 第一名到最後名次的車手編號
-}
+// 圈速表格子狀態
+const (
+	LapStatusRunning  = "running"
+	LapStatusPitted   = "pitted"   // 該圈進站
+	LapStatusLapped   = "lapped"   // 仍在場上，該圈結束時已被領先者套圈
+	LapStatusFinished = "finished" // 被套圈的完賽者在領先者衝線後沒有跑的圈
+	LapStatusRetired  = "retired"  // 已退賽
+)
 
 type PositionService struct {
 	*BaseService
@@ -48,148 +54,196 @@ func (s *PositionService) GetPositionHistory(ctx context.Context, sessionKey int
 	return driverHistory, nil
 }
 
-func (s *PositionService) GetSessionLapRankings(ctx context.Context, sessionKey int) ([]map[string]interface{}, error) {
+// =======================
+// 主入口: 圈位置圖
+// =======================
+
+// GetSessionLapChart 每圈結束時的名次與狀態，發車格取自 starting_grid (沒有時以第一筆名次代替)
+func (s *PositionService) GetSessionLapChart(ctx context.Context, sessionKey int) (*LapChart, error) {
 	positionHistory, err := s.GetPositionHistory(ctx, sessionKey)
 	if err != nil {
 		return nil, err
 	}
 
-	lapService := NewLapService(s.BaseService)
-	lapHistory, err := lapService.GetLapHistoryAll(ctx, sessionKey)
+	lapHistory, err := NewLapService(s.BaseService).GetLapHistoryAll(ctx, sessionKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// 收集所有圈數（取最大圈數為止）
-	maxLap := 0
-	for _, laps := range lapHistory {
-		for _, lap := range laps {
-			if lap.LapNumber > maxLap {
-				maxLap = lap.LapNumber
-			}
-		}
+	grid, err := fetchRecords[StartingGridRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetStartGridBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch starting grid, using first positions", zap.Int("session_key", sessionKey), zap.Error(err))
 	}
 
-	var result []map[string]interface{}
-	var prevRank []int
-	var prevDNF []bool
+	chart := buildLapChart(lapHistory, positionHistory, grid)
+	chart.SessionKey = sessionKey
+	return chart, nil
+}
 
-	for lapNum := 1; lapNum <= maxLap; lapNum++ {
-		type driverPos struct {
-			driver  int
-			pos     int
-			dnfTime time.Time
-			isDNF   bool
-		}
-		var list []driverPos
+// chartDriver 單一車手建表所需的索引資料
+type chartDriver struct {
+	number    int
+	lapEnd    []time.Time // 以圈數為索引的完成時間，零值表示沒有該圈
+	pitted    []bool      // 以圈數為索引，該圈是否進站
+	completed int         // 完成的圈數
+	retired   bool
+	finalPos  int
+}
 
-		// 檢查這一圈是否有 lapHistory
-		hasLapData := false
+// buildLapChart 單次走訪：每位車手的名次記錄以指標前進，套圈判定以各圈最早完成時間二分搜尋，
+// 整體為 O(圈數 × 車手數 + 名次記錄數)
+func buildLapChart(lapHistory map[int][]LapRecord, positionHistory map[int][]PositionRecord, grid []StartingGridRecord) *LapChart {
+	totalLaps := 0
+	for _, laps := range lapHistory {
+		if n := len(laps); n > 0 {
+			totalLaps = max(totalLaps, laps[n-1].LapNumber)
+		}
+	}
 
-		for driver, laps := range lapHistory {
-			if len(laps) == 0 {
+	// 各圈最早的完成時間 (領先者)，依圈數遞增
+	leaderEnd := make([]time.Time, totalLaps+1)
+	drivers := make([]*chartDriver, 0, len(lapHistory))
+	for num, laps := range lapHistory {
+		d := &chartDriver{
+			number: num,
+			lapEnd: make([]time.Time, totalLaps+1),
+			pitted: make([]bool, totalLaps+1),
+		}
+		for i, lap := range laps {
+			end := time.Time{}
+			if i+1 < len(laps) && laps[i+1].LapNumber == lap.LapNumber+1 && !laps[i+1].DateStart.IsZero() {
+				end = laps[i+1].DateStart
+				d.pitted[lap.LapNumber] = laps[i+1].IsPitOutLap
+			} else if !lap.DateStart.IsZero() && lap.LapDuration > 0 {
+				end = lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second)))
+			}
+			if end.IsZero() {
 				continue
 			}
+			d.lapEnd[lap.LapNumber] = end
+			d.completed = lap.LapNumber
+			if leaderEnd[lap.LapNumber].IsZero() || end.Before(leaderEnd[lap.LapNumber]) {
+				leaderEnd[lap.LapNumber] = end
+			}
+		}
+		drivers = append(drivers, d)
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i].number < drivers[j].number })
+
+	// 沒有任何車手計時資料的圈以下一個有資料的圈補上，讓二分搜尋的條件保持單調；
+	// 補上的時間只會晚於實際時間，落後圈數因此只可能少算不會多算
+	for lap := totalLaps - 1; lap >= 1; lap-- {
+		if leaderEnd[lap].IsZero() {
+			leaderEnd[lap] = leaderEnd[lap+1]
+		}
+	}
 
-			var lapTime time.Time
-			var isDNF bool
-			var latestPos int
-
-			if lapNum == 1 {
-				// 第一圈取 PositionHistory 最早一筆
-				if positions, ok := positionHistory[driver]; ok && len(positions) > 0 {
-					firstPos := positions[0]
-					lapTime = firstPos.Date
-					latestPos = firstPos.Position
-					isDNF = false
-					hasLapData = true
-				} else {
-					// 沒有任何 position，當作 DNF
-					lapTime = time.Time{}
-					latestPos = 999
-					isDNF = true
+	// 最後一圈在領先者衝線前結束 (或最後一筆圈沒有圈速) 視為退賽，否則為被套圈完賽
+	finish := leaderEnd[totalLaps]
+	for _, d := range drivers {
+		laps := lapHistory[d.number]
+		last := laps[len(laps)-1]
+		d.retired = d.completed < totalLaps &&
+			(last.LapNumber > d.completed || finish.IsZero() || d.lapEnd[d.completed].Before(finish))
+	}
+
+	chart := &LapChart{TotalLaps: totalLaps, Grid: chartGrid(grid, positionHistory)}
+	cursor := make(map[int]int, len(drivers))
+	for lap := 1; lap <= totalLaps; lap++ {
+		row := LapChartLap{Lap: lap, Cells: make([]LapChartCell, 0, len(drivers))}
+		for _, d := range drivers {
+			cell := LapChartCell{DriverNumber: d.number}
+			end := d.lapEnd[lap]
+			switch {
+			case end.IsZero() && d.retired:
+				cell.Status = LapStatusRetired
+				cell.Position = d.finalPos
+			case end.IsZero():
+				// 被套圈的完賽者沒有跑這一圈，沿用最後的名次與最終落後圈數
+				cell.Status = LapStatusFinished
+				cell.Position = d.finalPos
+				cell.LapsDown = totalLaps - d.completed
+			default:
+				history := positionHistory[d.number]
+				k := cursor[d.number]
+				for k < len(history) && !history[k].Date.After(end) {
+					k++
+				}
+				cursor[d.number] = k
+				if k > 0 {
+					cell.Position = history[k-1].Position
 				}
-			} else {
-				// 找對應圈數的 lap
-				found := false
-				for _, lap := range laps {
-					if lap.LapNumber == lapNum {
-						lapTime = lap.DateStart
-						isDNF = lap.IsDNF
-						latestPos = 999
-						for _, pos := range positionHistory[driver] {
-							if !pos.Date.After(lapTime) {
-								latestPos = pos.Position
-							} else {
-								break
-							}
-						}
-						found = true
-						hasLapData = true
-						break
-					}
+				d.finalPos = cell.Position
+
+				down := sort.Search(totalLaps+1, func(i int) bool {
+					return i > 0 && (leaderEnd[i].IsZero() || leaderEnd[i].After(end))
+				}) - 1 - lap
+				cell.Status = LapStatusRunning
+				if down > 0 {
+					cell.Status = LapStatusLapped
+					cell.LapsDown = down
 				}
-				if !found {
-					// 沒有這圈的 lap → 保留 last known pos
-					isDNF = true
-					lapTime = laps[len(laps)-1].DateStart
-					latestPos = 999
-					for _, pos := range positionHistory[driver] {
-						if !pos.Date.After(lapTime) {
-							latestPos = pos.Position
-						} else {
-							break
-						}
-					}
+				if d.pitted[lap] {
+					cell.Status = LapStatusPitted
 				}
 			}
-
-			list = append(list, driverPos{
-				driver:  driver,
-				pos:     latestPos,
-				dnfTime: lapTime,
-				isDNF:   isDNF,
-			})
+			row.Cells = append(row.Cells, cell)
 		}
-
-		if !hasLapData && lapNum > 1 {
-			// 這一圈沒有任何資料 → 繼承上一圈的結果
-			result = append(result, map[string]interface{}{
-				"lap":  lapNum,
-				"rank": prevRank,
-				"dnf":  prevDNF,
-			})
-			continue
+		sortLapChartCells(row.Cells, drivers)
+		for i := range row.Cells {
+			row.Cells[i].Position = i + 1
 		}
+		chart.Laps = append(chart.Laps, row)
+	}
 
-		// 排序
-		sort.Slice(list, func(i, j int) bool {
-			if list[i].isDNF && list[j].isDNF {
-				return list[i].dnfTime.After(list[j].dnfTime)
-			} else if list[i].isDNF {
-				return false
-			} else if list[j].isDNF {
-				return true
-			}
-			return list[i].pos < list[j].pos
-		})
+	for _, d := range drivers {
+		chart.Drivers = append(chart.Drivers, d.number)
+	}
+	return chart
+}
 
-		var rank []int
-		var dnf []bool
-		for _, p := range list {
-			rank = append(rank, p.driver)
-			dnf = append(dnf, p.isDNF)
+// sortLapChartCells 仍在場上的車依名次排序 (沒有名次者依完成圈數)，退賽者排在最後並依完成圈數排序
+func sortLapChartCells(cells []LapChartCell, drivers []*chartDriver) {
+	completed := make(map[int]int, len(drivers))
+	for _, d := range drivers {
+		completed[d.number] = d.completed
+	}
+	sort.SliceStable(cells, func(i, j int) bool {
+		a, b := cells[i], cells[j]
+		ra, rb := a.Status == LapStatusRetired, b.Status == LapStatusRetired
+		if ra != rb {
+			return rb
 		}
+		if !ra && a.Position > 0 && b.Position > 0 && a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		if (a.Position > 0) != (b.Position > 0) && !ra {
+			return a.Position > 0
+		}
+		return completed[a.DriverNumber] > completed[b.DriverNumber]
+	})
+}
 
-		prevRank = rank
-		prevDNF = dnf
-
-		result = append(result, map[string]interface{}{
-			"lap":  lapNum,
-			"rank": rank,
-			"dnf":  dnf,
-		})
+// chartGrid 優先使用 starting_grid；沒有資料時以每位車手最早一筆名次代替
+func chartGrid(grid []StartingGridRecord, positionHistory map[int][]PositionRecord) []GridSlot {
+	slots := make([]GridSlot, 0, len(grid))
+	for _, g := range grid {
+		slots = append(slots, GridSlot{Position: g.Position, DriverNumber: g.DriverNumber, LapDuration: g.LapDuration})
 	}
-
-	return result, nil
+	if len(slots) == 0 {
+		for num, history := range positionHistory {
+			if len(history) > 0 {
+				slots = append(slots, GridSlot{Position: history[0].Position, DriverNumber: num})
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Position != slots[j].Position {
+			return slots[i].Position < slots[j].Position
+		}
+		return slots[i].DriverNumber < slots[j].DriverNumber
+	})
+	return slots
 }
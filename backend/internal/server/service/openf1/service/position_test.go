@@ -0,0 +1,189 @@
+package service
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fullRaceDataset 產生一場 20 車、57 圈的正賽資料：每圈依累計時間重排名次並帶多筆重複的名次記錄
+// (接近 OpenF1 一場正賽約 5000 筆)，包含進站、兩輛退賽與被套圈的車
+func fullRaceDataset() (map[int][]LapRecord, map[int][]PositionRecord, []StartingGridRecord) {
+	const drivers, laps = 20, 57
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	type lapEnd struct {
+		driver, lap int
+		at          time.Time
+	}
+	lapHistory := make(map[int][]LapRecord, drivers)
+	var ends []lapEnd
+	var grid []StartingGridRecord
+	var finish time.Time // 領先者衝線時間；被套圈的車在此之後跑完當圈即結束
+
+	for d := 0; d < drivers; d++ {
+		num := d + 1
+		grid = append(grid, StartingGridRecord{Position: d + 1, DriverNumber: num, LapDuration: 88 + float64(d)*0.1})
+
+		lastLap := laps
+		switch num {
+		case 7:
+			lastLap = 18 // 退賽
+		case 13:
+			lastLap = 41 // 退賽
+		}
+
+		at := start
+		for lap := 1; lap <= lastLap; lap++ {
+			if !finish.IsZero() && at.After(finish) {
+				break
+			}
+			duration := 92 + float64(d)*0.25 + rng.Float64()*0.6
+			if lap == 20+d%6 {
+				duration += 21 // 進站
+			}
+			rec := LapRecord{
+				DateStart:    at,
+				DriverNumber: num,
+				LapNumber:    lap,
+				LapDuration:  duration,
+				IsPitOutLap:  lap == 21+d%6,
+			}
+			if lap == lastLap && lastLap < laps {
+				rec.LapDuration = 0
+			}
+			lapHistory[num] = append(lapHistory[num], rec)
+			at = at.Add(time.Duration(duration * float64(time.Second)))
+			if rec.LapDuration > 0 {
+				ends = append(ends, lapEnd{driver: num, lap: lap, at: at})
+			}
+		}
+		if d == 0 {
+			finish = at
+		}
+	}
+	sort.Slice(ends, func(i, j int) bool { return ends[i].at.Before(ends[j].at) })
+
+	completed := make(map[int]lapEnd, drivers)
+	positionHistory := make(map[int][]PositionRecord, drivers)
+	for _, e := range ends {
+		completed[e.driver] = e
+		order := make([]lapEnd, 0, len(completed))
+		for _, c := range completed {
+			order = append(order, c)
+		}
+		sort.Slice(order, func(i, j int) bool {
+			if order[i].lap != order[j].lap {
+				return order[i].lap > order[j].lap
+			}
+			return order[i].at.Before(order[j].at)
+		})
+		for pos, c := range order {
+			if c.driver == e.driver || rng.Intn(4) == 0 {
+				positionHistory[c.driver] = append(positionHistory[c.driver], PositionRecord{
+					Date:         e.at,
+					DriverNumber: c.driver,
+					Position:     pos + 1,
+				})
+			}
+		}
+	}
+	return lapHistory, positionHistory, grid
+}
+
+func BenchmarkBuildLapChart(b *testing.B) {
+	lapHistory, positionHistory, grid := fullRaceDataset()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildLapChart(lapHistory, positionHistory, grid)
+	}
+}
+
+// chartLaps 依圈速連續產生單圈記錄；圈速為 0 表示該圈沒有完成 (退賽)
+func chartLaps(num int, start time.Time, durations ...float64) []LapRecord {
+	laps := make([]LapRecord, 0, len(durations))
+	at := start
+	for i, duration := range durations {
+		laps = append(laps, LapRecord{DateStart: at, DriverNumber: num, LapNumber: i + 1, LapDuration: duration})
+		at = at.Add(time.Duration(duration * float64(time.Second)))
+	}
+	return laps
+}
+
+func TestBuildLapChart(t *testing.T) {
+	start := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	// 1 號領先跑完 4 圈；2 號第 2 圈進站、第 3 圈結束時落後一圈，領先者衝線後不再跑第 4 圈；
+	// 3 號第 3 圈退賽
+	race := func() map[int][]LapRecord {
+		lapHistory := map[int][]LapRecord{
+			1: chartLaps(1, start, 100, 100, 100, 100),
+			2: chartLaps(2, start, 150, 170, 150),
+			3: chartLaps(3, start, 105, 105, 0),
+		}
+		lapHistory[2][2].IsPitOutLap = true
+		return lapHistory
+	}
+	// 2 號每圈慢 50 秒，第 3 圈結束時落後一圈；第 2 圈所有車手都沒有計時資料
+	missingTiming := func() map[int][]LapRecord {
+		lapHistory := map[int][]LapRecord{
+			1: chartLaps(1, start, 100, 100, 100, 100),
+			2: chartLaps(2, start, 150, 150, 150, 150),
+		}
+		for _, laps := range lapHistory {
+			laps[1].DateStart = time.Time{}
+			laps[2].DateStart = time.Time{}
+		}
+		return lapHistory
+	}
+
+	positions := map[int][]PositionRecord{
+		1: {{Date: start, DriverNumber: 1, Position: 1}},
+		3: {{Date: start, DriverNumber: 3, Position: 2}},
+		2: {{Date: start, DriverNumber: 2, Position: 3}},
+	}
+
+	tests := []struct {
+		name     string
+		history  func() map[int][]LapRecord
+		lap      int
+		driver   int
+		status   string
+		lapsDown int
+	}{
+		{"leader running", race, 1, 1, LapStatusRunning, 0},
+		{"slower car still on lead lap", race, 1, 2, LapStatusRunning, 0},
+		{"pit lap keeps laps down", race, 2, 2, LapStatusPitted, 1},
+		{"lapped while running", race, 3, 2, LapStatusLapped, 1},
+		{"lap not driven after finish", race, 4, 2, LapStatusFinished, 1},
+		{"running before retirement", race, 2, 3, LapStatusRunning, 0},
+		{"retired on lap", race, 3, 3, LapStatusRetired, 0},
+		{"stays retired", race, 4, 3, LapStatusRetired, 0},
+		{"lapped across lap without timing", missingTiming, 3, 2, LapStatusLapped, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chart := buildLapChart(tt.history(), positions, nil)
+			if chart.TotalLaps != 4 {
+				t.Fatalf("total laps = %d, want 4", chart.TotalLaps)
+			}
+			var cell *LapChartCell
+			for i := range chart.Laps[tt.lap-1].Cells {
+				if c := &chart.Laps[tt.lap-1].Cells[i]; c.DriverNumber == tt.driver {
+					cell = c
+				}
+			}
+			if cell == nil {
+				t.Fatalf("lap %d has no cell for driver %d", tt.lap, tt.driver)
+			}
+			if cell.Status != tt.status || cell.LapsDown != tt.lapsDown {
+				t.Errorf("lap %d driver %d = %s (%d down), want %s (%d down)",
+					tt.lap, tt.driver, cell.Status, cell.LapsDown, tt.status, tt.lapsDown)
+			}
+		})
+	}
+}
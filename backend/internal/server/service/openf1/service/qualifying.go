@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// qualifying107Percent 排位賽第一節最快圈的 107% 為參賽門檻
+const qualifying107Percent = 1.07
+
+// qualifyingFinalCars 排位賽最後一節固定由 10 車進行
+const qualifyingFinalCars = 10
+
+type QualifyingService struct {
+	*BaseService
+}
+
+func NewQualifyingService(base *BaseService) *QualifyingService {
+	return &QualifyingService{BaseService: base}
+}
+
+// =======================
+// 主入口: 排位賽淘汰分析
+// =======================
+
+// GetSessionQualifying 以 race control 的出站口開啟與方格旗訊息切分 Q1 / Q2 / Q3 (衝刺排位賽為 SQ1 ~ SQ3，
+// 同樣以序號表示)，計算各節最佳圈、晉級門檻、107% 門檻、每趟出站的進步，以及誰把誰擠出晉級區
+func (s *QualifyingService) GetSessionQualifying(ctx context.Context, sessionKey int) (*QualifyingAnalysis, error) {
+	results, err := fetchRecords[QualifyingResult](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetResultBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []RaceControlEvent
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch race control, segments will come from results only", zap.Int("session_key", sessionKey), zap.Error(err))
+	} else {
+		events = ParseRaceControlEvents(records)
+	}
+
+	lapHistory, err := NewLapService(s.BaseService).GetLapHistoryAll(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch laps", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+	markDeletedLaps(lapHistory, events)
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	analysis := buildQualifyingAnalysis(ExtractQualifyingSegments(events), results, lapHistory, drivers)
+	analysis.SessionKey = sessionKey
+	return analysis, nil
+}
+
+// =======================
+// 分節擷取
+// =======================
+
+// ExtractQualifyingSegments 每節由方格旗之後第一個出站口開啟 (或 SESSION STARTED) 開始，到下一面方格旗結束；
+// 紅旗後重新開啟出站口仍屬同一節
+func ExtractQualifyingSegments(events []RaceControlEvent) []QualifyingSegment {
+	var segments []QualifyingSegment
+	var open *QualifyingSegment
+	for _, ev := range events {
+		switch ev.Type {
+		case EventSessionStart:
+			if open == nil {
+				open = &QualifyingSegment{Name: fmt.Sprintf("Q%d", len(segments)+1), Start: ev.UTC}
+			}
+		case EventChequeredFlag:
+			if open != nil {
+				end := ev.UTC
+				open.End = &end
+				segments = append(segments, *open)
+				open = nil
+			}
+		}
+	}
+	if open != nil {
+		segments = append(segments, *open)
+	}
+	return segments
+}
+
+// contains 圈在該節開始後、方格旗前出發即屬於該節 (方格旗後仍可完成正在跑的圈)
+func (seg QualifyingSegment) contains(lap LapRecord) bool {
+	return !lap.DateStart.IsZero() && !lap.DateStart.Before(seg.Start) && (seg.End == nil || lap.DateStart.Before(*seg.End))
+}
+
+// =======================
+// 分析
+// =======================
+
+// qualifyingEntry 單一車手在單節的計時圈
+type qualifyingEntry struct {
+	laps []LapRecord
+	best *float64
+}
+
+func buildQualifyingAnalysis(segments []QualifyingSegment, results []QualifyingResult, lapHistory map[int][]LapRecord, drivers map[int]Driver) *QualifyingAnalysis {
+	// 沒有 race control 分節時，以成績的節數建立沒有時間範圍的分節
+	if len(segments) == 0 {
+		count := 0
+		for _, r := range results {
+			count = max(count, len(r.Duration))
+		}
+		for i := 0; i < count; i++ {
+			segments = append(segments, QualifyingSegment{Name: fmt.Sprintf("Q%d", i+1)})
+		}
+	}
+
+	// 依成績排序車手，沒有成績的車手依車號排在最後
+	order := make([]int, 0, len(results))
+	resultIndex := make(map[int]QualifyingResult, len(results))
+	sortedResults := append([]QualifyingResult(nil), results...)
+	sort.SliceStable(sortedResults, func(i, j int) bool {
+		a, b := sortedResults[i], sortedResults[j]
+		if (a.Position > 0) != (b.Position > 0) {
+			return a.Position > 0
+		}
+		return a.Position < b.Position
+	})
+	for _, r := range sortedResults {
+		order = append(order, r.DriverNumber)
+		resultIndex[r.DriverNumber] = r
+	}
+	var extra []int
+	for num := range lapHistory {
+		if _, ok := resultIndex[num]; !ok {
+			extra = append(extra, num)
+		}
+	}
+	sort.Ints(extra)
+	order = append(order, extra...)
+
+	// 各節每位車手的計時圈與最佳圈：官方成績優先，沒有時取圈資料中最快的計時圈
+	entries := make([]map[int]*qualifyingEntry, len(segments))
+	for i, seg := range segments {
+		entries[i] = make(map[int]*qualifyingEntry)
+		for _, num := range order {
+			e := &qualifyingEntry{}
+			if !seg.Start.IsZero() {
+				for _, lap := range lapHistory[num] {
+					if seg.contains(lap) {
+						e.laps = append(e.laps, lap)
+					}
+				}
+			}
+			if r, ok := resultIndex[num]; ok && i < len(r.Duration) && r.Duration[i] != nil && *r.Duration[i] > 0 {
+				t := *r.Duration[i]
+				e.best = &t
+			}
+			if e.best == nil {
+				for _, lap := range e.laps {
+					if qualifyingTimedLap(lap) && (e.best == nil || lap.LapDuration < *e.best) {
+						t := lap.LapDuration
+						e.best = &t
+					}
+				}
+			}
+			if len(e.laps) > 0 || e.best != nil {
+				entries[i][num] = e
+			}
+		}
+	}
+
+	advance := qualifyingAdvance(results, len(segments))
+
+	analysis := &QualifyingAnalysis{Drivers: []QualifyingDriver{}}
+	driverIndex := make(map[int]*QualifyingDriver, len(order))
+	qDrivers := make([]QualifyingDriver, len(order))
+	for i, num := range order {
+		qd := QualifyingDriver{DriverNumber: num, Position: resultIndex[num].Position, Segments: []DriverQualifyingSegment{}}
+		if d, ok := drivers[num]; ok {
+			qd.NameAcronym = d.NameAcronym
+			qd.TeamName = d.Team
+			qd.Color = d.Color
+		}
+		qDrivers[i] = qd
+		driverIndex[num] = &qDrivers[i]
+	}
+
+	for i := range segments {
+		seg := &segments[i]
+		ranked := rankQualifyingEntries(entries[i], order)
+		seg.Participants = len(ranked)
+		if len(ranked) > 0 && entries[i][ranked[0]].best != nil {
+			fastest := *entries[i][ranked[0]].best
+			seg.FastestLap = &fastest
+			seg.FastestDriver = &ranked[0]
+		}
+		if i == 0 && seg.FastestLap != nil {
+			threshold := *seg.FastestLap * qualifying107Percent
+			seg.Threshold107 = &threshold
+		}
+
+		// 晉級與否以官方名次判斷：晉級但下一節沒有出賽 (車損或保留輪胎) 的車手不算被淘汰；
+		// 沒有官方名次時才以下一節是否有資料推斷。最後一節沒有淘汰
+		knockedOutBy := map[int]int{}
+		if i+1 < len(segments) {
+			advanced := func(num int) bool {
+				if advance != nil {
+					pos := resultIndex[num].Position
+					return pos > 0 && pos <= advance[i]
+				}
+				_, ok := entries[i+1][num]
+				return ok
+			}
+			seg.Advance = len(entries[i+1])
+			if advance != nil {
+				seg.Advance = advance[i]
+			}
+			seg.Eliminated = []int{}
+			for _, num := range ranked {
+				if !advanced(num) {
+					seg.Eliminated = append(seg.Eliminated, num)
+				}
+			}
+			for k := len(ranked) - 1; k >= 0; k-- {
+				if advanced(ranked[k]) {
+					if best := entries[i][ranked[k]].best; best != nil {
+						cutoff := *best
+						seg.CutoffTime = &cutoff
+						seg.CutoffDriver = &ranked[k]
+					}
+					break
+				}
+			}
+			knockedOutBy = replayKnockouts(entries[i], seg.Advance)
+		}
+
+		for pos, num := range ranked {
+			e := entries[i][num]
+			ds := DriverQualifyingSegment{Segment: seg.Name, Position: pos + 1, BestLap: e.best, Runs: qualifyingRuns(e.laps)}
+			if e.best != nil && seg.FastestLap != nil {
+				gap := *e.best - *seg.FastestLap
+				ds.GapToFastest = &gap
+			}
+			if e.best != nil && seg.CutoffTime != nil {
+				gap := *e.best - *seg.CutoffTime
+				ds.GapToCutoff = &gap
+			}
+
+			qd := driverIndex[num]
+			qd.Segments = append(qd.Segments, ds)
+			if i == 0 && e.best != nil && seg.Threshold107 != nil && *e.best > *seg.Threshold107 {
+				qd.Outside107 = true
+			}
+		}
+		for _, num := range seg.Eliminated {
+			qd := driverIndex[num]
+			qd.EliminatedIn = seg.Name
+			if by, ok := knockedOutBy[num]; ok {
+				qd.KnockedOutBy = &by
+			}
+		}
+	}
+
+	analysis.Segments = segments
+	analysis.Drivers = qDrivers
+	return analysis
+}
+
+// qualifyingAdvance 依賽制推算各節晉級人數：最後一節 10 車，其餘車手由前面各節平均淘汰
+// (20 車為 15 / 10，22 車為 16 / 10)，無法整除的多餘名額由第一節淘汰。沒有官方名次時回傳 nil
+func qualifyingAdvance(results []QualifyingResult, segments int) []int {
+	// 參賽車數包含取消資格 (沒有名次) 的車手
+	field := len(results)
+	if segments < 2 || field <= qualifyingFinalCars || !slices.ContainsFunc(results, func(r QualifyingResult) bool { return r.Position > 0 }) {
+		return nil
+	}
+
+	advance := make([]int, segments-1)
+	perSegment := (field - qualifyingFinalCars) / (segments - 1)
+	advance[segments-2] = qualifyingFinalCars
+	for i := segments - 3; i >= 0; i-- {
+		advance[i] = advance[i+1] + perSegment
+	}
+	return advance
+}
+
+// qualifyingTimedLap 完整、非出站圈且未被刪除的計時圈
+func qualifyingTimedLap(lap LapRecord) bool {
+	return lap.LapDuration > 0 && !lap.IsPitOutLap && !lap.Deleted
+}
+
+// rankQualifyingEntries 依最佳圈排序，沒有計時圈者依原本順序排在最後
+func rankQualifyingEntries(entries map[int]*qualifyingEntry, order []int) []int {
+	ranked := make([]int, 0, len(entries))
+	for _, num := range order {
+		if _, ok := entries[num]; ok {
+			ranked = append(ranked, num)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := entries[ranked[i]].best, entries[ranked[j]].best
+		if (a != nil) != (b != nil) {
+			return a != nil
+		}
+		return a != nil && *a < *b
+	})
+	return ranked
+}
+
+// qualifyingRuns 以出站圈切分每趟出站，進步為該趟最佳圈與之前最佳圈的差 (負數為變快)
+func qualifyingRuns(laps []LapRecord) []QualifyingRun {
+	runs := []QualifyingRun{}
+	var bestSoFar *float64
+	for i, lap := range laps {
+		if i == 0 || lap.IsPitOutLap {
+			if n := len(runs); n > 0 {
+				bestSoFar = closeQualifyingRun(&runs[n-1], bestSoFar)
+			}
+			runs = append(runs, QualifyingRun{Run: len(runs) + 1})
+		}
+		run := &runs[len(runs)-1]
+		if !qualifyingTimedLap(lap) {
+			continue
+		}
+		run.TimedLaps++
+		if run.BestLap == nil || lap.LapDuration < *run.BestLap {
+			t := lap.LapDuration
+			run.BestLap = &t
+		}
+	}
+	if n := len(runs); n > 0 {
+		closeQualifyingRun(&runs[n-1], bestSoFar)
+	}
+	return runs
+}
+
+func closeQualifyingRun(run *QualifyingRun, bestSoFar *float64) *float64 {
+	if run.BestLap == nil {
+		return bestSoFar
+	}
+	if bestSoFar != nil {
+		improvement := *run.BestLap - *bestSoFar
+		run.Improvement = &improvement
+		if *run.BestLap >= *bestSoFar {
+			return bestSoFar
+		}
+	}
+	return run.BestLap
+}
+
+// replayKnockouts 依完成時間重播計時圈：車手從晉級區外進入晉級區時，原本位於最後晉級名次的車手被擠出。
+// 回傳每位被擠出車手最後一次被誰擠出
+func replayKnockouts(entries map[int]*qualifyingEntry, advance int) map[int]int {
+	type completion struct {
+		driver   int
+		duration float64
+		at       time.Time
+	}
+	var completions []completion
+	for num, e := range entries {
+		for _, lap := range e.laps {
+			if qualifyingTimedLap(lap) {
+				end := lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second)))
+				completions = append(completions, completion{driver: num, duration: lap.LapDuration, at: end})
+			}
+		}
+	}
+	sort.Slice(completions, func(i, j int) bool { return completions[i].at.Before(completions[j].at) })
+
+	knockedOutBy := make(map[int]int)
+	if advance <= 0 {
+		return knockedOutBy
+	}
+	best := make(map[int]float64)
+	var standings []int // 目前有成績的車手，依最佳圈排序
+	for _, c := range completions {
+		prev, ok := best[c.driver]
+		if ok && c.duration >= prev {
+			continue
+		}
+		before := -1
+		for i, num := range standings {
+			if num == c.driver {
+				before = i
+				standings = append(standings[:i], standings[i+1:]...)
+				break
+			}
+		}
+		best[c.driver] = c.duration
+		at := sort.Search(len(standings), func(i int) bool { return best[standings[i]] > c.duration })
+		standings = append(standings, 0)
+		copy(standings[at+1:], standings[at:])
+		standings[at] = c.driver
+
+		wasOut := before < 0 || before >= advance
+		if wasOut && at < advance && len(standings) > advance {
+			knockedOutBy[standings[advance]] = c.driver
+		}
+	}
+	return knockedOutBy
+}
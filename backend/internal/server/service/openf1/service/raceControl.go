@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// race control 事件類型
+const (
+	EventFlag               = "flag"
+	EventBlueFlag           = "blue_flag"
+	EventChequeredFlag      = "chequered_flag"
+	EventSafetyCarDeployed  = "safety_car_deployed"
+	EventSafetyCarEnding    = "safety_car_ending"
+	EventVSCDeployed        = "vsc_deployed"
+	EventVSCEnding          = "vsc_ending"
+	EventDRSEnabled         = "drs_enabled"
+	EventDRSDisabled        = "drs_disabled"
+	EventTrackLimits        = "track_limits"
+	EventLapDeleted         = "lap_deleted"
+	EventLapReinstated      = "lap_reinstated"
+	EventIncidentNoted      = "incident_noted"
+	EventInvestigation      = "investigation"
+	EventNoFurtherAction    = "no_further_action"
+	EventPenalty            = "penalty"
+	EventPenaltyServed      = "penalty_served"
+	EventSessionStart       = "session_start"
+	EventSessionStopped     = "session_stopped"
+	EventSessionResume      = "session_resume"
+	EventSessionResumeTimed = "session_resume_time"
+	EventOther              = "other"
+)
+
+// 罰則類型
+const (
+	PenaltyTime         = "time"
+	PenaltyDriveThrough = "drive_through"
+	PenaltyStopGo       = "stop_go"
+	PenaltyGrid         = "grid"
+	PenaltyReprimand    = "reprimand"
+	PenaltyDisqualified = "disqualified"
+	PenaltyOther        = "other"
+)
+
+var (
+	rcCarPattern      = regexp.MustCompile(`\b(\d{1,2}) \(([A-Z]{3})\)`)
+	rcTurnPattern     = regexp.MustCompile(`\bTURN (\d+)`)
+	rcLapPattern      = regexp.MustCompile(`\bLAP (\d+)\b`)
+	rcLapTimePattern  = regexp.MustCompile(`\bTIME (\d+:\d{2}\.\d{3})`)
+	rcSecondsPattern  = regexp.MustCompile(`(\d+) SECOND`)
+	rcGridPattern     = regexp.MustCompile(`(\d+) PLACE GRID`)
+	rcReasonSeparator = " - "
+)
+
+type RaceControlService struct {
+	*BaseService
+}
+
+func NewRaceControlService(base *BaseService) *RaceControlService {
+	return &RaceControlService{BaseService: base}
+}
+
+// =======================
+// 主入口: 結構化 race control 事件
+// =======================
+
+// GetSessionEvents 解析 session 所有 race control 訊息；types 非空時只回傳指定類型
+func (s *RaceControlService) GetSessionEvents(ctx context.Context, sessionKey int, types ...string) ([]RaceControlEvent, error) {
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseRaceControlEvents(records, types...), nil
+}
+
+// ParseRaceControlEvents 依時間排序並解析訊息；types 非空時只保留指定類型
+func ParseRaceControlEvents(records []RaceControlRecord, types ...string) []RaceControlEvent {
+	sorted := append([]RaceControlRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	events := make([]RaceControlEvent, 0, len(sorted))
+	for _, rec := range sorted {
+		ev := parseRaceControl(rec)
+		if len(types) > 0 && !slices.Contains(types, ev.Type) {
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+// =======================
+// 訊息解析
+// =======================
+
+func parseRaceControl(rec RaceControlRecord) RaceControlEvent {
+	msg := strings.ToUpper(strings.TrimSpace(rec.Message))
+	ev := RaceControlEvent{
+		UTC:          rec.Date,
+		Lap:          rec.LapNumber,
+		Category:     rec.Category,
+		Flag:         rec.Flag,
+		Scope:        rec.Scope,
+		Sector:       rec.Sector,
+		Message:      rec.Message,
+		DriverNumber: rec.DriverNumber,
+		SessionKey:   rec.SessionKey,
+		MeetingKey:   rec.MeetingKey,
+	}
+
+	for _, m := range rcCarPattern.FindAllStringSubmatch(msg, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && !slices.Contains(ev.Drivers, n) {
+			ev.Drivers = append(ev.Drivers, n)
+		}
+	}
+	if ev.DriverNumber == nil && len(ev.Drivers) > 0 {
+		n := ev.Drivers[0]
+		ev.DriverNumber = &n
+	}
+	if m := rcTurnPattern.FindStringSubmatch(msg); m != nil {
+		turn, _ := strconv.Atoi(m[1])
+		ev.Turn = &turn
+	}
+
+	ev.Type = classifyRaceControl(rec, msg)
+
+	switch ev.Type {
+	case EventTrackLimits, EventLapDeleted, EventLapReinstated:
+		if m := rcLapTimePattern.FindStringSubmatch(msg); m != nil {
+			ev.DeletedTime = m[1]
+		}
+		// 刪圈訊息常在下一圈才發布，以訊息中的圈數為準
+		if m := rcLapPattern.FindStringSubmatch(msg); m != nil {
+			ev.Lap, _ = strconv.Atoi(m[1])
+		}
+		ev.Reason = reasonAfterSeparator(msg)
+	case EventIncidentNoted, EventInvestigation, EventNoFurtherAction, EventPenaltyServed:
+		ev.Reason = reasonAfterSeparator(msg)
+	case EventPenalty:
+		ev.Penalty = parsePenalty(msg)
+		ev.Reason = ev.Penalty.Reason
+	}
+	return ev
+}
+
+func classifyRaceControl(rec RaceControlRecord, msg string) string {
+	flag := strings.ToUpper(rec.Flag)
+	category := strings.ToUpper(rec.Category)
+
+	switch {
+	case strings.Contains(msg, "VIRTUAL SAFETY CAR DEPLOYED"):
+		return EventVSCDeployed
+	case strings.Contains(msg, "VIRTUAL SAFETY CAR ENDING"):
+		return EventVSCEnding
+	case strings.Contains(msg, "SAFETY CAR DEPLOYED"):
+		return EventSafetyCarDeployed
+	case strings.Contains(msg, "SAFETY CAR IN THIS LAP") || strings.Contains(msg, "SAFETY CAR ENDING"):
+		return EventSafetyCarEnding
+	case strings.HasPrefix(msg, "DRS ENABLED"):
+		return EventDRSEnabled
+	case strings.HasPrefix(msg, "DRS DISABLED"):
+		return EventDRSDisabled
+	case strings.Contains(msg, "REINSTATED"):
+		return EventLapReinstated
+	case strings.Contains(msg, "DELETED") && strings.Contains(msg, "TRACK LIMITS"):
+		return EventTrackLimits
+	case strings.Contains(msg, "DELETED"):
+		return EventLapDeleted
+	case strings.Contains(msg, "PENALTY SERVED"):
+		return EventPenaltyServed
+	case strings.Contains(msg, "PENALTY") || strings.Contains(msg, "REPRIMAND") || strings.Contains(msg, "DISQUALIFIED"):
+		return EventPenalty
+	case strings.Contains(msg, "NO FURTHER") || strings.Contains(msg, "NO INVESTIGATION"):
+		return EventNoFurtherAction
+	case strings.Contains(msg, "UNDER INVESTIGATION") || strings.Contains(msg, "WILL BE INVESTIGATED"):
+		return EventInvestigation
+	case strings.Contains(msg, "NOTED"):
+		return EventIncidentNoted
+	case strings.Contains(msg, "SESSION WILL RESUME") || strings.Contains(msg, "WILL RESTART AT"):
+		return EventSessionResumeTimed
+	case strings.Contains(msg, "SESSION RESUMED") || strings.Contains(msg, "RESTART"):
+		return EventSessionResume
+	case flag == "RED" || strings.Contains(msg, "SESSION SUSPENDED") || strings.Contains(msg, "SESSION STOPPED"):
+		return EventSessionStopped
+	case strings.Contains(msg, "GREEN LIGHT - PIT EXIT OPEN") || strings.Contains(msg, "SESSION STARTED"):
+		return EventSessionStart
+	case flag == "CHEQUERED":
+		return EventChequeredFlag
+	case flag == "BLUE":
+		return EventBlueFlag
+	case category == "FLAG" || flag != "":
+		return EventFlag
+	}
+	return EventOther
+}
+
+// parsePenalty 解析罰則類型、秒數或退後名次，原因為 " - " 之後的文字
+func parsePenalty(msg string) *RacePenalty {
+	p := &RacePenalty{Type: PenaltyOther, Reason: reasonAfterSeparator(msg)}
+	switch {
+	case strings.Contains(msg, "DRIVE THROUGH"):
+		p.Type = PenaltyDriveThrough
+	case strings.Contains(msg, "STOP/GO") || strings.Contains(msg, "STOP GO") || strings.Contains(msg, "STOP AND GO"):
+		p.Type = PenaltyStopGo
+	case strings.Contains(msg, "GRID"):
+		p.Type = PenaltyGrid
+	case strings.Contains(msg, "TIME PENALTY"):
+		p.Type = PenaltyTime
+	case strings.Contains(msg, "REPRIMAND"):
+		p.Type = PenaltyReprimand
+	case strings.Contains(msg, "DISQUALIFIED"):
+		p.Type = PenaltyDisqualified
+	}
+	if m := rcSecondsPattern.FindStringSubmatch(msg); m != nil {
+		p.Seconds, _ = strconv.Atoi(m[1])
+	}
+	if m := rcGridPattern.FindStringSubmatch(msg); m != nil {
+		p.GridPlaces, _ = strconv.Atoi(m[1])
+	}
+	return p
+}
+
+func reasonAfterSeparator(msg string) string {
+	if i := strings.Index(msg, rcReasonSeparator); i >= 0 {
+		return strings.TrimSpace(msg[i+len(rcReasonSeparator):])
+	}
+	return ""
+}
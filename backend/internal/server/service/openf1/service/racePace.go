@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RacePaceOptions 燃油修正與異常圈過濾設定
+type RacePaceOptions struct {
+	FuelCorrection   float64 `json:"fuel_correction"`   // 每少一圈燃油可快多少秒
+	OutlierThreshold float64 `json:"outlier_threshold"` // 慢於車手乾淨圈中位數此倍數視為異常，0 表示不過濾
+	MinStintLaps     int     `json:"min_stint_laps"`    // 擬合衰退斜率所需的最少圈數
+}
+
+// DefaultRacePaceOptions 約 1.8 kg/圈、0.033 s/kg 的燃油修正
+func DefaultRacePaceOptions() RacePaceOptions {
+	return RacePaceOptions{
+		FuelCorrection:   0.06,
+		OutlierThreshold: 1.07,
+		MinStintLaps:     3,
+	}
+}
+
+// 排除圈的原因
+const (
+	excludeLapOne       = "lap_1"
+	excludePitLap       = "pit_in_out"
+	excludeNeutralised  = "neutralised"
+	excludeOutlier      = "outlier"
+	excludeMissingTime  = "missing_time"
+	excludeUnknownStint = "unknown_stint"
+)
+
+type RacePaceService struct {
+	*BaseService
+}
+
+func NewRacePaceService(base *BaseService) *RacePaceService {
+	return &RacePaceService{BaseService: base}
+}
+
+// =======================
+// 主入口: 正賽配速與輪胎衰退
+// =======================
+func (s *RacePaceService) GetSessionRacePace(ctx context.Context, sessionKey int, opts RacePaceOptions) (*SessionRacePace, error) {
+	// 圈已標記 SC / VSC / 紅旗；race control 取得失敗時不排除
+	lapHistory, periods, err := NewLapService(s.BaseService).GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stints, err := NewStintService(s.BaseService).GetStintsBySession(ctx, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	totalLaps := 0
+	for _, laps := range lapHistory {
+		for _, lap := range laps {
+			totalLaps = max(totalLaps, lap.LapNumber)
+		}
+	}
+
+	result := &SessionRacePace{
+		SessionKey:      sessionKey,
+		TotalLaps:       totalLaps,
+		Options:         opts,
+		NeutralisedLaps: neutralisedLapNumbers(periods),
+	}
+	for driverNum, laps := range lapHistory {
+		pace := analyseDriverPace(laps, stints[driverNum], totalLaps, opts)
+		pace.DriverNumber = driverNum
+		if d, ok := drivers[driverNum]; ok {
+			pace.NameAcronym = d.NameAcronym
+			pace.TeamName = d.Team
+			pace.Color = d.Color
+		}
+		result.Drivers = append(result.Drivers, pace)
+	}
+	sort.Slice(result.Drivers, func(i, j int) bool {
+		return result.Drivers[i].DriverNumber < result.Drivers[j].DriverNumber
+	})
+
+	return result, nil
+}
+
+// =======================
+// 圈速清理
+// =======================
+
+// paceLap 通過過濾的圈，已做燃油修正
+type paceLap struct {
+	lapNumber int
+	tyreAge   int
+	corrected float64
+	stint     StintRecord
+}
+
+// cleanRaceLaps 排除第一圈、進出站圈、中立化圈與異常慢圈，回傳修正後的圈與各原因排除數
+func cleanRaceLaps(laps []LapRecord, stints []StintRecord, totalLaps int, opts RacePaceOptions) ([]paceLap, map[string]int) {
+	excluded := make(map[string]int)
+
+	pitLaps := make(map[int]bool)
+	for i, st := range stints {
+		if i > 0 {
+			pitLaps[st.LapStart] = true // 出站圈
+		}
+		if i < len(stints)-1 {
+			pitLaps[st.LapEnd] = true // 進站圈
+		}
+	}
+
+	var candidates []LapRecord
+	var durations []float64
+	for _, lap := range laps {
+		switch {
+		case lap.LapNumber <= 1:
+			excluded[excludeLapOne]++
+		case lap.LapDuration <= 0:
+			excluded[excludeMissingTime]++
+		case lap.IsPitOutLap || pitLaps[lap.LapNumber]:
+			excluded[excludePitLap]++
+		case lap.Neutralised != "":
+			excluded[excludeNeutralised]++
+		default:
+			candidates = append(candidates, lap)
+			durations = append(durations, lap.LapDuration)
+		}
+	}
+
+	limit := 0.0
+	if opts.OutlierThreshold > 0 {
+		limit = median(durations) * opts.OutlierThreshold
+	}
+
+	var clean []paceLap
+	for _, lap := range candidates {
+		if limit > 0 && lap.LapDuration > limit {
+			excluded[excludeOutlier]++
+			continue
+		}
+		st, ok := stintForLap(stints, lap.LapNumber)
+		if !ok {
+			excluded[excludeUnknownStint]++
+			continue
+		}
+		clean = append(clean, paceLap{
+			lapNumber: lap.LapNumber,
+			tyreAge:   st.TyreAgeAtStart + lap.LapNumber - st.LapStart,
+			corrected: lap.LapDuration - opts.FuelCorrection*float64(totalLaps-lap.LapNumber),
+			stint:     st,
+		})
+	}
+	return clean, excluded
+}
+
+func stintForLap(stints []StintRecord, lapNumber int) (StintRecord, bool) {
+	for _, st := range stints {
+		if lapNumber >= st.LapStart && (st.LapEnd == 0 || lapNumber <= st.LapEnd) {
+			return st, true
+		}
+	}
+	return StintRecord{}, false
+}
+
+// =======================
+// 配速與衰退擬合
+// =======================
+
+func analyseDriverPace(laps []LapRecord, stints []StintRecord, totalLaps int, opts RacePaceOptions) DriverRacePace {
+	clean, excluded := cleanRaceLaps(laps, stints, totalLaps, opts)
+	pace := DriverRacePace{
+		CleanLaps:    len(clean),
+		ExcludedLaps: excluded,
+	}
+
+	byStint := make(map[int][]paceLap)
+	for _, l := range clean {
+		byStint[l.stint.StintNumber] = append(byStint[l.stint.StintNumber], l)
+	}
+
+	type compoundAcc struct {
+		times      []float64
+		slopeSum   float64
+		slopeLaps  int
+		firstStint int
+	}
+	compounds := make(map[string]*compoundAcc)
+
+	for _, st := range stints {
+		stintLaps := byStint[st.StintNumber]
+		sp := StintPace{
+			StintNumber:    st.StintNumber,
+			Compound:       st.Compound,
+			LapStart:       st.LapStart,
+			LapEnd:         st.LapEnd,
+			TyreAgeAtStart: st.TyreAgeAtStart,
+			CleanLaps:      len(stintLaps),
+		}
+		if len(stintLaps) == 0 {
+			pace.Stints = append(pace.Stints, sp)
+			continue
+		}
+
+		ages := make([]float64, len(stintLaps))
+		times := make([]float64, len(stintLaps))
+		for i, l := range stintLaps {
+			ages[i] = float64(l.tyreAge)
+			times[i] = l.corrected
+		}
+		sp.MeanPace = mean(times)
+		if len(stintLaps) >= opts.MinStintLaps {
+			if slope, intercept, ok := linearFit(ages, times); ok {
+				sp.Degradation = slope
+				sp.PaceAtNewTyre = intercept
+				sp.Fitted = true
+			}
+		}
+		pace.Stints = append(pace.Stints, sp)
+
+		compound := strings.ToUpper(st.Compound)
+		acc, ok := compounds[compound]
+		if !ok {
+			acc = &compoundAcc{firstStint: st.StintNumber}
+			compounds[compound] = acc
+		}
+		acc.times = append(acc.times, times...)
+		if sp.Fitted {
+			acc.slopeSum += sp.Degradation * float64(sp.CleanLaps)
+			acc.slopeLaps += sp.CleanLaps
+		}
+	}
+
+	for compound, acc := range compounds {
+		cp := CompoundPace{
+			Compound:   compound,
+			Laps:       len(acc.times),
+			MeanPace:   mean(acc.times),
+			MedianPace: median(acc.times),
+		}
+		if acc.slopeLaps > 0 {
+			cp.Degradation = acc.slopeSum / float64(acc.slopeLaps)
+			cp.DegradationFitted = true
+		}
+		pace.Compounds = append(pace.Compounds, cp)
+	}
+	sort.Slice(pace.Compounds, func(i, j int) bool {
+		return compounds[pace.Compounds[i].Compound].firstStint < compounds[pace.Compounds[j].Compound].firstStint
+	})
+
+	return pace
+}
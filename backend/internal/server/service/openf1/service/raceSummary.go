@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 正賽結果狀態
+const (
+	RaceStatusFinished = "finished"
+	RaceStatusDNF      = "dnf"
+	RaceStatusDNS      = "dns"
+	RaceStatusDSQ      = "dsq"
+)
+
+const (
+	// raceStartWindow 第一段沒有時間資料時，以正賽開始後此時間的名次作為起跑後名次
+	raceStartWindow = 20 * time.Second
+	// raceMoversLimit 單場最大得失與賽季排行列出的人數
+	raceMoversLimit = 5
+	// seasonMoversMinRaces 列入賽季排行所需的最少場次 (賽季場次較少時以賽季場次為準)
+	seasonMoversMinRaces = 3
+)
+
+type RaceSummaryService struct {
+	*BaseService
+}
+
+func NewRaceSummaryService(base *BaseService) *RaceSummaryService {
+	return &RaceSummaryService{BaseService: base}
+}
+
+// =======================
+// 主入口: 單場 / 整個賽季
+// =======================
+
+// GetSessionRaceSummary 比較發車位置、起跑後、第一圈結束與最終名次，列出單場名次得失最多的車手
+func (s *RaceSummaryService) GetSessionRaceSummary(ctx context.Context, sessionKey int) (*RaceSummary, error) {
+	return s.loadRaceSummary(ctx, sessionKey)
+}
+
+// GetSeasonRaceSummary 彙整賽季每場正賽的名次得失，排出最會起跑與正賽追分最多的車手
+func (s *RaceSummaryService) GetSeasonRaceSummary(ctx context.Context, year int) (*SeasonRaceSummary, error) {
+	sessions, err := s.getPastSessions(ctx, year, "Race")
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*RaceSummary, len(sessions))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func(i int, sess Session) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			summary, err := s.loadRaceSummary(ctx, sess.SessionKey)
+			if err != nil {
+				s.Logger.Warn("Failed to load race summary", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+				return
+			}
+			summaries[i] = summary
+		}(i, sess)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return aggregateRaceSummaries(year, summaries), nil
+}
+
+// loadRaceSummary 正賽結果為必要資料；發車格、名次、圈資料與車手名單缺少時只略過對應欄位
+func (s *RaceSummaryService) loadRaceSummary(ctx context.Context, sessionKey int) (*RaceSummary, error) {
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	results, err := fetchRecords[SessionResult](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetResultBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	grid, err := fetchRecords[StartingGridRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetStartGridBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		s.Logger.Warn("Failed to fetch starting grid", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	positions, err := NewPositionService(s.BaseService).GetPositionHistory(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch positions", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	laps, err := NewLapService(s.BaseService).GetLapHistoryAll(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch race laps", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return nil, err
+	}
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	return buildRaceSummary(sessionKey, results, grid, positions, laps, drivers), nil
+}
+
+// =======================
+// 單場計算
+// =======================
+
+// buildRaceSummary 起跑後名次取第一段結束 (沒有分段時間時為正賽開始後 raceStartWindow) 的名次，
+// 第一圈名次取該車完成第一圈時的名次。維修區起跑視為從最後一格出發。
+func buildRaceSummary(sessionKey int, results []SessionResult, grid []StartingGridRecord, positions map[int][]PositionRecord, laps map[int][]LapRecord, drivers map[int]Driver) *RaceSummary {
+	summary := &RaceSummary{SessionKey: sessionKey, Drivers: []RacePositionChange{}}
+
+	gridIndex := make(map[int]int, len(grid))
+	for _, g := range grid {
+		gridIndex[g.DriverNumber] = g.Position
+	}
+	pitLaneSlot := max(len(results), len(grid))
+
+	// 正賽開始時間：所有車手第一圈最早的開始時間
+	var raceStart time.Time
+	for _, driverLaps := range laps {
+		if len(driverLaps) > 0 && driverLaps[0].LapNumber == 1 && !driverLaps[0].DateStart.IsZero() &&
+			(raceStart.IsZero() || driverLaps[0].DateStart.Before(raceStart)) {
+			raceStart = driverLaps[0].DateStart
+		}
+	}
+
+	for _, r := range results {
+		c := RacePositionChange{DriverNumber: r.DriverNumber, Status: raceStatus(r)}
+		if d, ok := drivers[r.DriverNumber]; ok {
+			c.NameAcronym = d.NameAcronym
+			c.TeamName = d.Team
+			c.Color = d.Color
+		}
+		if c.Status == RaceStatusDNS {
+			summary.Drivers = append(summary.Drivers, c)
+			continue
+		}
+
+		if pos, ok := gridIndex[r.DriverNumber]; ok {
+			c.GridPosition = pos
+		} else if len(grid) > 0 {
+			c.PitLaneStart = true
+			c.GridPosition = pitLaneSlot
+		}
+
+		history := positions[r.DriverNumber]
+		if startAt, ok := startTimingPoint(laps[r.DriverNumber], raceStart); ok {
+			c.StartPosition = positionAt(history, startAt)
+		}
+		if driverLaps := laps[r.DriverNumber]; len(driverLaps) > 0 && driverLaps[0].LapNumber == 1 {
+			if _, end, ok := lapTimeRange(driverLaps, 0); ok {
+				c.Lap1Position = positionAt(history, end)
+			}
+		}
+		if c.Status == RaceStatusFinished {
+			c.FinishPosition = r.Position
+		}
+
+		c.StartGained = positionsGained(c.GridPosition, c.StartPosition)
+		c.Lap1Gained = positionsGained(c.GridPosition, c.Lap1Position)
+		c.NetGained = positionsGained(c.GridPosition, c.FinishPosition)
+		summary.Drivers = append(summary.Drivers, c)
+	}
+
+	sort.SliceStable(summary.Drivers, func(i, j int) bool {
+		a, b := summary.Drivers[i], summary.Drivers[j]
+		if (a.FinishPosition > 0) != (b.FinishPosition > 0) {
+			return a.FinishPosition > 0
+		}
+		if a.FinishPosition != b.FinishPosition {
+			return a.FinishPosition < b.FinishPosition
+		}
+		return a.DriverNumber < b.DriverNumber
+	})
+
+	summary.Gainers, summary.Losers = raceMovers(summary.Drivers)
+	return summary
+}
+
+// startTimingPoint 該車第一圈第一段結束的時間；沒有分段資料時以正賽開始後 raceStartWindow 為準
+func startTimingPoint(driverLaps []LapRecord, raceStart time.Time) (time.Time, bool) {
+	if len(driverLaps) > 0 {
+		lap := driverLaps[0]
+		if lap.LapNumber == 1 && !lap.DateStart.IsZero() && lap.DurationSector1 > 0 {
+			return lap.DateStart.Add(time.Duration(lap.DurationSector1 * float64(time.Second))), true
+		}
+	}
+	if raceStart.IsZero() {
+		return time.Time{}, false
+	}
+	return raceStart.Add(raceStartWindow), true
+}
+
+func raceStatus(r SessionResult) string {
+	switch {
+	case r.DSQ:
+		return RaceStatusDSQ
+	case r.DNS:
+		return RaceStatusDNS
+	case r.DNF:
+		return RaceStatusDNF
+	default:
+		return RaceStatusFinished
+	}
+}
+
+// positionsGained 正數為進步；任一名次未知時為 nil
+func positionsGained(from, to int) *int {
+	if from <= 0 || to <= 0 {
+		return nil
+	}
+	gained := from - to
+	return &gained
+}
+
+// raceMovers 依淨得名次排出進步與退步最多的完賽車手
+func raceMovers(changes []RacePositionChange) (gainers, losers []RacePositionChange) {
+	gainers, losers = []RacePositionChange{}, []RacePositionChange{}
+	var finished []RacePositionChange
+	for _, c := range changes {
+		if c.NetGained != nil {
+			finished = append(finished, c)
+		}
+	}
+
+	sort.SliceStable(finished, func(i, j int) bool { return *finished[i].NetGained > *finished[j].NetGained })
+	for _, c := range finished {
+		if *c.NetGained <= 0 || len(gainers) == raceMoversLimit {
+			break
+		}
+		gainers = append(gainers, c)
+	}
+	for i := len(finished) - 1; i >= 0; i-- {
+		c := finished[i]
+		if *c.NetGained >= 0 || len(losers) == raceMoversLimit {
+			break
+		}
+		losers = append(losers, c)
+	}
+	return gainers, losers
+}
+
+// =======================
+// 賽季彙整
+// =======================
+
+// driverRaceDayTotals 累計值與各項有效樣本數 (缺資料的場次不列入平均)
+type driverRaceDayTotals struct {
+	stats                           DriverRaceDayStats
+	startRaces, lap1Races, netRaces int
+}
+
+func aggregateRaceSummaries(year int, summaries []*RaceSummary) *SeasonRaceSummary {
+	result := &SeasonRaceSummary{Year: year, Drivers: []DriverRaceDayStats{}}
+	totals := make(map[int]*driverRaceDayTotals)
+
+	for _, summary := range summaries {
+		if summary == nil {
+			continue
+		}
+		result.Races++
+		for _, c := range summary.Drivers {
+			if c.Status == RaceStatusDNS {
+				continue
+			}
+			t, ok := totals[c.DriverNumber]
+			if !ok {
+				t = &driverRaceDayTotals{stats: DriverRaceDayStats{DriverNumber: c.DriverNumber}}
+				totals[c.DriverNumber] = t
+			}
+			// 名稱與車隊以最近一場為準
+			if c.NameAcronym != "" {
+				t.stats.NameAcronym = c.NameAcronym
+				t.stats.TeamName = c.TeamName
+				t.stats.Color = c.Color
+			}
+			t.stats.Races++
+			if c.StartGained != nil {
+				t.stats.StartGained += *c.StartGained
+				t.startRaces++
+			}
+			if c.Lap1Gained != nil {
+				t.stats.Lap1Gained += *c.Lap1Gained
+				t.lap1Races++
+			}
+			if c.NetGained != nil {
+				t.stats.NetGained += *c.NetGained
+				t.netRaces++
+				if *c.NetGained > 0 {
+					t.stats.RacesGained++
+				}
+			}
+		}
+	}
+
+	minRaces := min(seasonMoversMinRaces, result.Races)
+	eligible := []DriverRaceDayStats{}
+	for _, t := range totals {
+		if t.startRaces > 0 {
+			t.stats.AvgStartGained = float64(t.stats.StartGained) / float64(t.startRaces)
+		}
+		if t.lap1Races > 0 {
+			t.stats.AvgLap1Gained = float64(t.stats.Lap1Gained) / float64(t.lap1Races)
+		}
+		if t.netRaces > 0 {
+			t.stats.AvgNetGained = float64(t.stats.NetGained) / float64(t.netRaces)
+		}
+		result.Drivers = append(result.Drivers, t.stats)
+		if t.stats.Races >= minRaces {
+			eligible = append(eligible, t.stats)
+		}
+	}
+
+	sortBy := func(list []DriverRaceDayStats, primary, secondary func(DriverRaceDayStats) float64) {
+		sort.Slice(list, func(i, j int) bool {
+			if a, b := primary(list[i]), primary(list[j]); a != b {
+				return a > b
+			}
+			if a, b := secondary(list[i]), secondary(list[j]); a != b {
+				return a > b
+			}
+			return list[i].DriverNumber < list[j].DriverNumber
+		})
+	}
+	avgNet := func(d DriverRaceDayStats) float64 { return d.AvgNetGained }
+	avgLap1 := func(d DriverRaceDayStats) float64 { return d.AvgLap1Gained }
+	avgStart := func(d DriverRaceDayStats) float64 { return d.AvgStartGained }
+
+	sortBy(result.Drivers, avgNet, avgLap1)
+
+	// 起跑：第一圈結束的平均得失為主，起跑後名次為次
+	starters := append([]DriverRaceDayStats(nil), eligible...)
+	sortBy(starters, avgLap1, avgStart)
+	result.BestStarters = starters[:min(raceMoversLimit, len(starters))]
+
+	climbers := append([]DriverRaceDayStats(nil), eligible...)
+	sortBy(climbers, avgNet, avgLap1)
+	result.BestClimbers = climbers[:min(raceMoversLimit, len(climbers))]
+
+	return result
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"lovdlwlrma/backend/internal/server/service/race"
+
+	"go.uber.org/zap"
+)
+
+type ScenarioService struct {
+	*StandingsService
+	raceService *race.Service
+}
+
+func NewScenarioService(standings *StandingsService, raceService *race.Service) *ScenarioService {
+	return &ScenarioService{
+		StandingsService: standings,
+		raceService:      raceService,
+	}
+}
+
+// =======================
+// 主入口: 冠軍情境
+// =======================
+func (s *ScenarioService) GetChampionshipScenarios(ctx context.Context, year int) (*ChampionshipScenarios, error) {
+	history, err := s.GetStandingsHistory(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	races, err := s.raceService.GetRacesByYear(year)
+	if err != nil {
+		s.logger.Error("Failed to fetch race calendar", zap.Int("year", year), zap.Error(err))
+		return nil, err
+	}
+
+	remaining := remainingScoringEvents(races, awaitingResults(history.Rounds), time.Now())
+	s.logger.Debug("Remaining scoring events", zap.Int("year", year), zap.Int("count", len(remaining)))
+
+	result := &ChampionshipScenarios{
+		Year:            year,
+		DataComplete:    history.Complete,
+		RemainingEvents: remaining,
+	}
+	for _, round := range history.Rounds {
+		if round.Status == RoundStatusComplete {
+			result.CompletedRounds++
+		}
+	}
+	for _, ev := range remaining {
+		if ev.IsSprint {
+			result.RemainingSprints++
+		} else {
+			result.RemainingRaces++
+		}
+	}
+
+	driverBudget := remainingPointsBudget(year, remaining, 1)
+	teamBudget := remainingPointsBudget(year, remaining, 2)
+
+	// 缺漏場次之後的積分未計入積分榜，這些場次的最高可得積分視為仍未確定，避免誤判封王
+	for i := range driverBudget {
+		driverBudget[i] += withheldPointsBudget(year, history, 1)
+		teamBudget[i] += withheldPointsBudget(year, history, 2)
+	}
+	result.Drivers = buildTitleContenders(driverEntries(history), driverBudget[0])
+	result.Teams = buildTitleContenders(teamEntries(history), teamBudget[0])
+
+	if len(remaining) > 0 {
+		next := remaining[0]
+		table, fastestLap := eventPointsTable(year, next)
+		result.NextEvent = &next
+		result.DriverClinch = buildClinchScenario(result.Drivers, table, fastestLap, driverBudget[1], 1)
+		result.TeamClinch = buildClinchScenario(result.Teams, table, fastestLap, teamBudget[1], 2)
+	}
+
+	return result, nil
+}
+
+// =======================
+// 剩餘賽程
+// =======================
+
+// awaitingRound 已開賽但尚未公布結果的場次
+type awaitingRound struct {
+	start    time.Time
+	isSprint bool
+}
+
+// awaitingResults 從積分榜各站狀態取出已開賽但仍在等待結果的場次
+func awaitingResults(rounds []RoundStatus) []awaitingRound {
+	var awaiting []awaitingRound
+	for _, round := range rounds {
+		if round.Status != RoundStatusPending {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, round.DateStart)
+		if err != nil {
+			continue
+		}
+		awaiting = append(awaiting, awaitingRound{start: start, isSprint: round.SessionName == "Sprint"})
+	}
+	return awaiting
+}
+
+// isAwaiting 賽歷場次與等待結果的場次在同一天內開始即視為同一場
+func isAwaiting(awaiting []awaitingRound, start time.Time, isSprint bool) bool {
+	for _, a := range awaiting {
+		diff := a.start.Sub(start)
+		if a.isSprint == isSprint && diff > -24*time.Hour && diff < 24*time.Hour {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingScoringEvents 從賽歷中取出尚未產生結果的 Race/Sprint，Sprint 與正賽分開計算。
+// 已開賽但官方尚未公布結果的場次仍算剩餘場次，避免同時從已完成場次與剩餘積分中消失。
+func remainingScoringEvents(races []*race.Race, awaiting []awaitingRound, now time.Time) []ScoringEvent {
+	var events []ScoringEvent
+	for _, r := range races {
+		hasRace := false
+		for _, sess := range r.Sessions {
+			isSprint := isSprintSession(sess)
+			if !isSprint && !isRaceSession(sess) {
+				continue
+			}
+			if !isSprint {
+				hasRace = true
+			}
+			start := sess.StartDate
+			if start == nil {
+				start = r.StartDate
+			}
+			if start == nil || (!start.After(now) && !isAwaiting(awaiting, *start, isSprint)) {
+				continue
+			}
+			events = append(events, ScoringEvent{
+				Round:    r.Round,
+				Name:     r.Name,
+				IsSprint: isSprint,
+				Date:     start,
+			})
+		}
+
+		// 賽歷尚未公佈場次時，以大獎賽本身當作一場正賽
+		if !hasRace && r.StartDate != nil && (r.StartDate.After(now) || isAwaiting(awaiting, *r.StartDate, false)) {
+			events = append(events, ScoringEvent{
+				Round: r.Round,
+				Name:  r.Name,
+				Date:  r.StartDate,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(*events[j].Date)
+	})
+	return events
+}
+
+func isSprintSession(sess race.Session) bool {
+	return strings.EqualFold(sess.Type, "sprint") || strings.EqualFold(sess.Name, "sprint")
+}
+
+func isRaceSession(sess race.Session) bool {
+	return strings.EqualFold(sess.Type, "race") || strings.EqualFold(sess.Name, "race")
+}
+
+// =======================
+// 積分規則
+// =======================
+
+func eventPointsTable(year int, ev ScoringEvent) ([]float64, float64) {
+	return OfficialPointsSystem(year).Table(ev.IsSprint)
+}
+
+// maxEventPoints 單場比賽 cars 台車最多可拿的積分
+func maxEventPoints(table []float64, fastestLap float64, cars int) float64 {
+	total := 0.0
+	for i := 0; i < cars && i < len(table); i++ {
+		total += table[i]
+	}
+	if len(table) > 0 {
+		total += fastestLap
+	}
+	return total
+}
+
+// remainingPointsBudget 回傳 [全部剩餘場次, 下一場之後] 的最大可得積分
+func remainingPointsBudget(year int, events []ScoringEvent, cars int) [2]float64 {
+	var budget [2]float64
+	for i, ev := range events {
+		table, fastestLap := eventPointsTable(year, ev)
+		pts := maxEventPoints(table, fastestLap, cars)
+		budget[0] += pts
+		if i > 0 {
+			budget[1] += pts
+		}
+	}
+	return budget
+}
+
+// withheldPointsBudget 已開賽但未計入積分榜的場次 (第一個缺漏場次之後) 的最大可得積分
+func withheldPointsBudget(year int, history *StandingsHistory, cars int) float64 {
+	total := 0.0
+	for _, round := range history.Rounds[min(history.ScoredRounds, len(history.Rounds)):] {
+		if round.Status == RoundStatusPending {
+			continue
+		}
+		table, fastestLap := OfficialPointsSystem(year).Table(round.SessionName == "Sprint")
+		total += maxEventPoints(table, fastestLap, cars)
+	}
+	return total
+}
+
+// =======================
+// 車手 / 車隊爭冠資格
+// =======================
+
+func driverEntries(history *StandingsHistory) []TitleContender {
+	entries := make([]TitleContender, 0, len(history.DriverStandings))
+	for _, d := range history.DriverStandings {
+		entries = append(entries, TitleContender{
+			DriverNumber: d.DriverNumber,
+			Name:         d.FullName,
+			TeamName:     d.TeamName,
+			Points:       lastPoints(d.CumulativePoints),
+		})
+	}
+	return entries
+}
+
+// teamEntries 以每站該車手代表的車隊累加積分，賽季中轉隊的車手積分分屬前後兩隊
+func teamEntries(history *StandingsHistory) []TitleContender {
+	teamPoints := make(map[string]float64)
+	var order []string
+	for _, d := range history.DriverStandings {
+		for i, pts := range d.RoundPoints {
+			team := d.TeamName
+			if i < len(d.RoundTeams) && d.RoundTeams[i] != "" {
+				team = d.RoundTeams[i]
+			}
+			if _, ok := teamPoints[team]; !ok {
+				order = append(order, team)
+			}
+			teamPoints[team] += pts
+		}
+	}
+
+	entries := make([]TitleContender, 0, len(order))
+	for _, team := range order {
+		entries = append(entries, TitleContender{
+			Name:     team,
+			TeamName: team,
+			Points:   teamPoints[team],
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Points > entries[j].Points
+	})
+	return entries
+}
+
+func lastPoints(cumulative []float64) float64 {
+	if len(cumulative) == 0 {
+		return 0
+	}
+	return cumulative[len(cumulative)-1]
+}
+
+// buildTitleContenders 計算每位車手/車隊的理論最高積分，最高積分追平領先者仍視為有機會 (交由 countback 決定)
+func buildTitleContenders(entries []TitleContender, remaining float64) []TitleContender {
+	if len(entries) == 0 {
+		return entries
+	}
+	leader := entries[0].Points
+	for i := range entries {
+		entries[i].GapToLeader = leader - entries[i].Points
+		entries[i].MaxPoints = entries[i].Points + remaining
+		entries[i].CanWinTitle = entries[i].MaxPoints >= leader
+	}
+	return entries
+}
+
+// =======================
+// 下一場封王條件
+// =======================
+
+// buildClinchScenario 計算領先者在下一場封王所需的成績。
+// 保守估計：最快圈加分只算給對手，不算給領先者。
+func buildClinchScenario(contenders []TitleContender, table []float64, fastestLap, maxAfter float64, cars int) *ClinchScenario {
+	if len(contenders) == 0 {
+		return nil
+	}
+	leader := contenders[0]
+	scenario := &ClinchScenario{
+		DriverNumber: leader.DriverNumber,
+		Name:         leader.Name,
+		Clinched:     true,
+	}
+
+	var rivals []TitleContender
+	for _, c := range contenders[1:] {
+		if c.CanWinTitle {
+			scenario.Clinched = false
+			rivals = append(rivals, c)
+		}
+	}
+	if scenario.Clinched {
+		return scenario
+	}
+
+	// 領先者必須在下一場比對手多拿超過 margin 分
+	for _, r := range rivals {
+		scenario.RequiredMargins = append(scenario.RequiredMargins, RivalMargin{
+			DriverNumber: r.DriverNumber,
+			Name:         r.Name,
+			Margin:       r.Points + maxAfter - leader.Points,
+		})
+	}
+
+	// 無條件封王：對手拿到剩餘名次中的最佳成績仍追不上
+	outside := len(table) + 1
+	for _, taken := range leaderFinishes(outside, cars) {
+		leaderPts := finishPoints(table, taken)
+		rivalBest := bestAvailablePoints(table, fastestLap, taken, cars)
+		if clinchesAgainstAll(leader.Points+leaderPts, rivals, rivalBest, maxAfter) {
+			if !scenario.CanClinch || leaderPts < scenario.MinPointsToClinch {
+				scenario.MinPointsToClinch = leaderPts
+			}
+			scenario.CanClinch = true
+		}
+	}
+
+	if cars == 1 {
+		scenario.Combinations = driverClinchCombinations(leader, rivals, table, fastestLap, maxAfter)
+		if len(scenario.Combinations) > 0 {
+			scenario.CanClinch = true
+		}
+	}
+	return scenario
+}
+
+// leaderFinishes 列舉 cars 台車所有可能的名次組合，outside 表示未進入積分區 (可重複)
+func leaderFinishes(outside, cars int) [][]int {
+	if cars == 1 {
+		finishes := make([][]int, 0, outside)
+		for p := 1; p <= outside; p++ {
+			finishes = append(finishes, []int{p})
+		}
+		return finishes
+	}
+
+	var finishes [][]int
+	for a := 1; a <= outside; a++ {
+		for b := a; b <= outside; b++ {
+			if a == b && a != outside {
+				continue
+			}
+			finishes = append(finishes, []int{a, b})
+		}
+	}
+	return finishes
+}
+
+func finishPoints(table []float64, positions []int) float64 {
+	total := 0.0
+	for _, p := range positions {
+		if p >= 1 && p <= len(table) {
+			total += table[p-1]
+		}
+	}
+	return total
+}
+
+// bestAvailablePoints 排除已被佔用的名次後，cars 台車最多可拿的積分 (含最快圈)
+func bestAvailablePoints(table []float64, fastestLap float64, taken []int, cars int) float64 {
+	total := 0.0
+	got := 0
+	for p := 1; p <= len(table) && got < cars; p++ {
+		if containsInt(taken, p) {
+			continue
+		}
+		total += table[p-1]
+		got++
+	}
+	if got > 0 {
+		total += fastestLap
+	}
+	return total
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func clinchesAgainstAll(leaderTotal float64, rivals []TitleContender, rivalNext, maxAfter float64) bool {
+	for _, r := range rivals {
+		if r.Points+rivalNext+maxAfter >= leaderTotal {
+			return false
+		}
+	}
+	return true
+}
+
+// driverClinchCombinations 以領先者名次、最快圈得主與各對手名次的聯合結果找出封王的最少條件。
+// 給定領先者名次與最快圈得主後，每位對手能否阻止封王只取決於自己的名次，封王的聯合結果即為
+// 各對手允許名次的乘積；積分區外可容納任意多台車，名次互斥不會讓條件無解。最快圈只有一人拿到，
+// 因此每位對手分別列出有無最快圈的條件，而不是假設所有對手同時拿到最快圈加分。
+// 領先者名次越差條件越嚴格，一旦某名次無法封王即停止。
+func driverClinchCombinations(leader TitleContender, rivals []TitleContender, table []float64, fastestLap, maxAfter float64) []ClinchCombination {
+	outside := len(table) + 1
+	var combos []ClinchCombination
+
+	for pl := 1; pl <= outside; pl++ {
+		leaderPts := finishPoints(table, []int{pl})
+		leaderTotal := leader.Points + leaderPts
+		combo := ClinchCombination{
+			LeaderPosition: pl,
+			LeaderPoints:   leaderPts,
+		}
+
+		// 對手拿到任何名次都無法阻止封王時不列出條件
+		firstAllowed := 1
+		if pl == 1 {
+			firstAllowed = 2
+		}
+
+		possible := true
+		for _, r := range rivals {
+			without := clinchThreshold(r, leaderTotal, pl, table, 0, maxAfter)
+			with := clinchThreshold(r, leaderTotal, pl, table, fastestLap, maxAfter)
+			if without == 0 || with == 0 {
+				possible = false
+				break
+			}
+			if with <= firstAllowed {
+				continue
+			}
+			cond := RivalCondition{
+				DriverNumber:   r.DriverNumber,
+				Name:           r.Name,
+				FinishNoHigher: max(without, firstAllowed),
+			}
+			if with != cond.FinishNoHigher {
+				cond.FinishNoHigherWithFastestLap = with
+			}
+			combo.RivalConditions = append(combo.RivalConditions, cond)
+		}
+		if !possible {
+			break
+		}
+		combos = append(combos, combo)
+	}
+
+	return combos
+}
+
+// clinchThreshold 回傳對手最高可拿到第幾名領先者仍能封王，bonus 為該對手拿到的最快圈加分
+// (積分區外不計)；任何名次都無法封王時回傳 0
+func clinchThreshold(r TitleContender, leaderTotal float64, leaderPos int, table []float64, bonus, maxAfter float64) int {
+	outside := len(table) + 1
+	for pr := 1; pr <= outside; pr++ {
+		if pr == leaderPos && pr != outside {
+			continue
+		}
+		rivalPts := finishPoints(table, []int{pr})
+		if pr <= len(table) {
+			rivalPts += bonus
+		}
+		if r.Points+rivalPts+maxAfter < leaderTotal {
+			return pr
+		}
+	}
+	return 0
+}
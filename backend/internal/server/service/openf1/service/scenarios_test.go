@@ -0,0 +1,73 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildClinchScenario(t *testing.T) {
+	table := []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+	const fastestLap = 1.0
+
+	tests := []struct {
+		name         string
+		rival        float64 // 對手目前積分，領先者固定 400 分
+		maxAfter     float64 // 下一場之後剩餘的最高積分
+		clinched     bool
+		canClinch    bool
+		minPoints    float64
+		combinations int
+		conditions   map[int][]RivalCondition // 領先者名次 → 對手條件
+	}{
+		{
+			name:     "already clinched",
+			rival:    300,
+			maxAfter: 26,
+			clinched: true,
+		},
+		{
+			name:         "clinch with enough points or rival results",
+			rival:        350,
+			maxAfter:     26,
+			canClinch:    true,
+			minPoints:    4, // 第 8 名：404 分，對手第 1 名加最快圈最多 402 分
+			combinations: 11,
+			conditions: map[int][]RivalCondition{
+				1:  nil,
+				9:  {{DriverNumber: 2, Name: "B", FinishNoHigher: 1, FinishNoHigherWithFastestLap: 2}},
+				11: {{DriverNumber: 2, Name: "B", FinishNoHigher: 2}},
+			},
+		},
+		{
+			name:     "rival within reach even if leader wins",
+			rival:    380,
+			maxAfter: 52,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contenders := buildTitleContenders([]TitleContender{
+				{DriverNumber: 1, Name: "A", Points: 400},
+				{DriverNumber: 2, Name: "B", Points: tt.rival},
+			}, 26+tt.maxAfter)
+
+			scenario := buildClinchScenario(contenders, table, fastestLap, tt.maxAfter, 1)
+			if scenario.Clinched != tt.clinched || scenario.CanClinch != tt.canClinch {
+				t.Fatalf("clinched=%v canClinch=%v, want %v %v", scenario.Clinched, scenario.CanClinch, tt.clinched, tt.canClinch)
+			}
+			if scenario.MinPointsToClinch != tt.minPoints {
+				t.Errorf("min points to clinch = %v, want %v", scenario.MinPointsToClinch, tt.minPoints)
+			}
+			if len(scenario.Combinations) != tt.combinations {
+				t.Fatalf("combinations = %d, want %d", len(scenario.Combinations), tt.combinations)
+			}
+			for _, combo := range scenario.Combinations {
+				want, ok := tt.conditions[combo.LeaderPosition]
+				if ok && !reflect.DeepEqual(combo.RivalConditions, want) {
+					t.Errorf("leader P%d conditions = %+v, want %+v", combo.LeaderPosition, combo.RivalConditions, want)
+				}
+			}
+		})
+	}
+}
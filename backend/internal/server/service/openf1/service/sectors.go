@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+type SectorService struct {
+	*BaseService
+}
+
+func NewSectorService(base *BaseService) *SectorService {
+	return &SectorService{BaseService: base}
+}
+
+// =======================
+// 主入口: 分段分析
+// =======================
+
+// GetSessionSectors 計算每位車手的最佳分段、理論最快圈與對理想圈的差距
+func (s *SectorService) GetSessionSectors(ctx context.Context, sessionKey int, filter LapFilter) (*SessionSectors, error) {
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	result := &SessionSectors{SessionKey: sessionKey}
+	for driverNum, laps := range lapHistory {
+		ds := driverBestSectors(laps)
+		ds.DriverNumber = driverNum
+		if d, ok := drivers[driverNum]; ok {
+			ds.NameAcronym = d.NameAcronym
+			ds.TeamName = d.Team
+			ds.Color = d.Color
+		}
+		result.Drivers = append(result.Drivers, ds)
+	}
+
+	result.IdealLap = idealLap(result.Drivers)
+	rankSectors(result)
+
+	return result, nil
+}
+
+// =======================
+// 最佳分段
+// =======================
+
+// driverBestSectors 取每個分段的最佳時間；出站圈的第一段含維修區，刪除圈整圈不列入
+func driverBestSectors(laps []LapRecord) DriverSectors {
+	ds := DriverSectors{}
+	for i := range ds.BestSectors {
+		ds.BestSectors[i].Sector = i + 1
+	}
+
+	for _, lap := range laps {
+		if lap.Deleted {
+			continue
+		}
+		for i, d := range lap.SectorDurations() {
+			if d <= 0 || (i == 0 && lap.IsPitOutLap) {
+				continue
+			}
+			best := &ds.BestSectors[i]
+			if best.Duration == 0 || d < best.Duration {
+				best.Duration = d
+				best.LapNumber = lap.LapNumber
+			}
+		}
+		if lap.LapDuration > 0 && !lap.IsPitOutLap && (ds.BestLap == 0 || lap.LapDuration < ds.BestLap) {
+			ds.BestLap = lap.LapDuration
+			ds.BestLapNumber = lap.LapNumber
+		}
+	}
+
+	complete := true
+	for _, best := range ds.BestSectors {
+		if best.Duration == 0 {
+			complete = false
+			break
+		}
+		ds.TheoreticalBest += best.Duration
+	}
+	if !complete {
+		ds.TheoreticalBest = 0
+		return ds
+	}
+	if ds.BestLap > 0 {
+		ds.LostToTheoretical = ds.BestLap - ds.TheoreticalBest
+	}
+	return ds
+}
+
+// idealLap 全場每個分段的最佳時間組成的理想圈
+func idealLap(drivers []DriverSectors) IdealLap {
+	ideal := IdealLap{}
+	for i := range ideal.Sectors {
+		ideal.Sectors[i].Sector = i + 1
+	}
+
+	for _, d := range drivers {
+		for i, best := range d.BestSectors {
+			cur := &ideal.Sectors[i]
+			if best.Duration == 0 {
+				continue
+			}
+			if cur.Duration == 0 || best.Duration < cur.Duration ||
+				(best.Duration == cur.Duration && d.DriverNumber < cur.DriverNumber) {
+				cur.Duration = best.Duration
+				cur.DriverNumber = d.DriverNumber
+				cur.NameAcronym = d.NameAcronym
+				cur.LapNumber = best.LapNumber
+			}
+		}
+	}
+
+	for _, sec := range ideal.Sectors {
+		if sec.Duration == 0 {
+			ideal.Duration = 0
+			return ideal
+		}
+		ideal.Duration += sec.Duration
+	}
+	return ideal
+}
+
+// =======================
+// 排名
+// =======================
+
+// rankSectors 填入各分段排名、對最佳分段的差距與理論最快圈排名
+func rankSectors(result *SessionSectors) {
+	drivers := result.Drivers
+
+	for i := range result.Rankings {
+		result.Rankings[i].Sector = i + 1
+
+		var ranked []int
+		for j := range drivers {
+			if drivers[j].BestSectors[i].Duration > 0 {
+				ranked = append(ranked, j)
+			}
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			da, db := drivers[ranked[a]], drivers[ranked[b]]
+			if da.BestSectors[i].Duration != db.BestSectors[i].Duration {
+				return da.BestSectors[i].Duration < db.BestSectors[i].Duration
+			}
+			return da.DriverNumber < db.DriverNumber
+		})
+
+		for pos, j := range ranked {
+			best := &drivers[j].BestSectors[i]
+			best.Rank = pos + 1
+			best.GapToBest = best.Duration - result.IdealLap.Sectors[i].Duration
+			result.Rankings[i].Entries = append(result.Rankings[i].Entries, SectorRankingEntry{
+				Position:     pos + 1,
+				DriverNumber: drivers[j].DriverNumber,
+				NameAcronym:  drivers[j].NameAcronym,
+				Duration:     best.Duration,
+				GapToBest:    best.GapToBest,
+			})
+		}
+	}
+
+	for i := range drivers {
+		if drivers[i].TheoreticalBest > 0 && result.IdealLap.Duration > 0 {
+			drivers[i].GapToIdeal = drivers[i].TheoreticalBest - result.IdealLap.Duration
+		}
+	}
+
+	// 依理論最快圈排序，沒有完整分段者排在最後
+	sort.Slice(drivers, func(i, j int) bool {
+		ti, tj := drivers[i].TheoreticalBest, drivers[j].TheoreticalBest
+		if (ti > 0) != (tj > 0) {
+			return ti > 0
+		}
+		if ti != tj {
+			return ti < tj
+		}
+		return drivers[i].DriverNumber < drivers[j].DriverNumber
+	})
+	pos := 0
+	for i := range drivers {
+		if drivers[i].TheoreticalBest > 0 {
+			pos++
+			drivers[i].TheoreticalRank = pos
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+
+	"go.uber.org/zap"
+)
+
+// 測速點
+const (
+	SpeedPointI1        = "i1"
+	SpeedPointI2        = "i2"
+	SpeedPointST        = "st"
+	SpeedPointTelemetry = "car_data" // 遙測最高速
+)
+
+var (
+	lapSpeedPoints     = []string{SpeedPointI1, SpeedPointI2, SpeedPointST}
+	sessionSpeedPoints = []string{SpeedPointI1, SpeedPointI2, SpeedPointST, SpeedPointTelemetry}
+)
+
+// 遙測只抓比 speed trap 極速的第 75 百分位再慢 telemetrySpeedMargin 以內的樣本；
+// 不用最低值，避免一台進站圈或受損的慢車拉低門檻而抓下大半個 session
+const (
+	telemetrySpeedPercentile = 0.75
+	telemetrySpeedMargin     = 15
+)
+
+type SpeedTrapService struct {
+	*BaseService
+}
+
+func NewSpeedTrapService(base *BaseService) *SpeedTrapService {
+	return &SpeedTrapService{BaseService: base}
+}
+
+// =======================
+// 主入口: 單一 session 測速
+// =======================
+
+// GetSessionSpeedTraps 每位車手在各測速點的最高與中位數速度，以及各測速點排名
+func (s *SpeedTrapService) GetSessionSpeedTraps(ctx context.Context, sessionKey int, filter LapFilter) (*SessionSpeedTraps, error) {
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	result := &SessionSpeedTraps{SessionKey: sessionKey}
+	for driverNum, laps := range lapHistory {
+		ds := driverSpeedTraps(laps)
+		ds.DriverNumber = driverNum
+		if d, ok := drivers[driverNum]; ok {
+			ds.NameAcronym = d.NameAcronym
+			ds.TeamName = d.Team
+			ds.Color = d.Color
+		}
+		result.Drivers = append(result.Drivers, ds)
+	}
+
+	telemetryTop, err := s.telemetryTopSpeeds(ctx, sessionKey, result.Drivers)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch telemetry top speeds", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+	for i := range result.Drivers {
+		if top, ok := telemetryTop[result.Drivers[i].DriverNumber]; ok {
+			result.Drivers[i].Points = append(result.Drivers[i].Points, SpeedPoint{
+				Point: SpeedPointTelemetry,
+				Top:   top,
+			})
+		}
+	}
+
+	sort.Slice(result.Drivers, func(i, j int) bool {
+		return result.Drivers[i].DriverNumber < result.Drivers[j].DriverNumber
+	})
+	for _, point := range sessionSpeedPoints {
+		if ranking := rankSpeedPoint(result.Drivers, point); len(ranking.Entries) > 0 {
+			result.Rankings = append(result.Rankings, ranking)
+		}
+	}
+
+	return result, nil
+}
+
+// driverSpeedTraps 依圈資料計算 i1、i2、st 的最高與中位數速度
+func driverSpeedTraps(laps []LapRecord) DriverSpeedTraps {
+	ds := DriverSpeedTraps{}
+	for _, point := range lapSpeedPoints {
+		var speeds []float64
+		sp := SpeedPoint{Point: point}
+		for _, lap := range laps {
+			v := lapSpeed(lap, point)
+			if v <= 0 {
+				continue
+			}
+			speeds = append(speeds, float64(v))
+			if v > sp.Top {
+				sp.Top = v
+				sp.TopLap = lap.LapNumber
+			}
+		}
+		if len(speeds) == 0 {
+			continue
+		}
+		sp.Median = median(speeds)
+		sp.Samples = len(speeds)
+		ds.Points = append(ds.Points, sp)
+	}
+	return ds
+}
+
+func lapSpeed(lap LapRecord, point string) int {
+	switch point {
+	case SpeedPointI1:
+		return lap.I1Speed
+	case SpeedPointI2:
+		return lap.I2Speed
+	case SpeedPointST:
+		return lap.STSpeed
+	}
+	return 0
+}
+
+// telemetryTopSpeeds 以 car_data 找出每位車手的最高速；只抓接近全場極速的樣本
+func (s *SpeedTrapService) telemetryTopSpeeds(ctx context.Context, sessionKey int, drivers []DriverSpeedTraps) (map[int]int, error) {
+	var tops []float64
+	for _, d := range drivers {
+		for _, p := range d.Points {
+			if p.Point == SpeedPointST && p.Top > 0 {
+				tops = append(tops, float64(p.Top))
+			}
+		}
+	}
+	if len(tops) == 0 {
+		return nil, nil
+	}
+	// 極速低於門檻的車手不會有遙測樣本，只保留 speed trap 排名
+	threshold := int(percentile(tops, telemetrySpeedPercentile))
+
+	samples, err := fetchRecords[datasource.CarData](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetCarDataAboveSpeed(ctx, sessionKey, threshold-telemetrySpeedMargin)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	top := make(map[int]int)
+	for _, sample := range samples {
+		if sample.Speed > top[sample.DriverNumber] {
+			top[sample.DriverNumber] = sample.Speed
+		}
+	}
+	return top, nil
+}
+
+// rankSpeedPoint 依最高速排名單一測速點
+func rankSpeedPoint(drivers []DriverSpeedTraps, point string) SpeedTrapRanking {
+	ranking := SpeedTrapRanking{Point: point}
+	for i := range drivers {
+		for _, p := range drivers[i].Points {
+			if p.Point == point {
+				ranking.Entries = append(ranking.Entries, SpeedTrapEntry{
+					DriverNumber: drivers[i].DriverNumber,
+					NameAcronym:  drivers[i].NameAcronym,
+					TeamName:     drivers[i].TeamName,
+					Top:          p.Top,
+					Median:       p.Median,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(ranking.Entries, func(i, j int) bool {
+		return ranking.Entries[i].Top > ranking.Entries[j].Top
+	})
+	for i := range ranking.Entries {
+		ranking.Entries[i].Position = i + 1
+		ranking.Entries[i].GapToFastest = ranking.Entries[0].Top - ranking.Entries[i].Top
+	}
+	return ranking
+}
+
+// =======================
+// 主入口: 賽季車隊極速差距
+// =======================
+
+// GetSeasonSpeedDeficit 每個 session 以車隊最快 speed trap 對比全場最快，計算賽季平均極速差距。
+// 差距大的車隊通常使用高下壓力設定。
+func (s *SpeedTrapService) GetSeasonSpeedDeficit(ctx context.Context, year int, sessionName string) (*SeasonSpeedDeficit, error) {
+	sessions, err := s.getPastSessions(ctx, year, sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	type sessionTops struct {
+		session Session
+		teams   map[string]int
+		colors  map[string]string
+	}
+	tops := make([]*sessionTops, len(sessions))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func(i int, sess Session) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if s.throttle(ctx) != nil {
+				return
+			}
+			lapHistory, err := NewLapService(s.BaseService).GetLapHistoryAll(ctx, sess.SessionKey)
+			if err != nil {
+				s.Logger.Warn("Failed to fetch laps for speed traps", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+				return
+			}
+			if s.throttle(ctx) != nil {
+				return
+			}
+			drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sess.SessionKey)
+			if err != nil {
+				s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sess.SessionKey), zap.Error(err))
+				return
+			}
+
+			st := &sessionTops{session: sess, teams: make(map[string]int), colors: make(map[string]string)}
+			for driverNum, laps := range lapHistory {
+				d, ok := drivers[driverNum]
+				if !ok || d.Team == "" {
+					continue
+				}
+				for _, lap := range laps {
+					if lap.STSpeed > st.teams[d.Team] {
+						st.teams[d.Team] = lap.STSpeed
+						st.colors[d.Team] = d.Color
+					}
+				}
+			}
+			tops[i] = st
+		}(i, sess)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SeasonSpeedDeficit{Year: year, SessionName: sessionName}
+	teams := make(map[string]*TeamSpeedDeficit)
+	var order []string
+	for _, st := range tops {
+		if st == nil || len(st.teams) == 0 {
+			continue
+		}
+		result.Sessions++
+
+		fastest := 0
+		for _, top := range st.teams {
+			fastest = max(fastest, top)
+		}
+		for team, top := range st.teams {
+			td, ok := teams[team]
+			if !ok {
+				td = &TeamSpeedDeficit{TeamName: team}
+				teams[team] = td
+				order = append(order, team)
+			}
+			td.Color = st.colors[team]
+			td.Rounds = append(td.Rounds, SessionTeamSpeed{
+				SessionKey: st.session.SessionKey,
+				MeetingKey: st.session.MeetingKey,
+				Location:   st.session.Location,
+				TopSpeed:   top,
+				Deficit:    fastest - top,
+			})
+		}
+	}
+
+	for _, team := range order {
+		td := teams[team]
+		speeds := make([]float64, len(td.Rounds))
+		deficits := make([]float64, len(td.Rounds))
+		for i, r := range td.Rounds {
+			speeds[i] = float64(r.TopSpeed)
+			deficits[i] = float64(r.Deficit)
+		}
+		td.AverageTopSpeed = mean(speeds)
+		td.AverageDeficit = mean(deficits)
+		result.Teams = append(result.Teams, *td)
+	}
+	sort.Slice(result.Teams, func(i, j int) bool {
+		if result.Teams[i].AverageDeficit != result.Teams[j].AverageDeficit {
+			return result.Teams[i].AverageDeficit < result.Teams[j].AverageDeficit
+		}
+		return result.Teams[i].TeamName < result.Teams[j].TeamName
+	})
+
+	return result, nil
+}
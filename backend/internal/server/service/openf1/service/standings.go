@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -13,20 +14,13 @@ import (
 
 type StandingsService struct {
 	*BaseService
-	rateLimiter *time.Ticker
-	mu          sync.Mutex
-	logger      *zap.Logger
-
-	driverCache map[int]*Driver
-	cacheMu     sync.RWMutex
+	logger *zap.Logger
 }
 
 func NewStandingsService(base *BaseService, logger *zap.Logger) *StandingsService {
 	return &StandingsService{
 		BaseService: base,
-		rateLimiter: time.NewTicker(350 * time.Millisecond), // 每秒最多 3 次
 		logger:      logger,
-		driverCache: make(map[int]*Driver),
 	}
 }
 
@@ -34,19 +28,43 @@ func NewStandingsService(base *BaseService, logger *zap.Logger) *StandingsServic
 // 主入口: 積分歷史
 // =======================
 func (s *StandingsService) GetStandingsHistory(ctx context.Context, year int) (*StandingsHistory, error) {
-	s.logger.Info("Fetching standings history", zap.Int("year", year))
+	return s.getStandingsHistory(ctx, year, nil)
+}
 
-	// 預先載入 driver 資料
-	if err := s.preloadDrivers(ctx); err != nil {
-		s.logger.Warn("Failed to preload drivers", zap.Error(err))
-	}
+// GetWhatIfStandings 以指定積分制度與覆寫條件重新計算積分榜
+func (s *StandingsService) GetWhatIfStandings(ctx context.Context, year int, opts *WhatIfOptions) (*StandingsHistory, error) {
+	return s.getStandingsHistory(ctx, year, opts)
+}
+
+func (s *StandingsService) getStandingsHistory(ctx context.Context, year int, opts *WhatIfOptions) (*StandingsHistory, error) {
+	s.logger.Info("Fetching standings history", zap.Int("year", year))
 
-	sessions, resultsMap, err := s.getRaceSessionsAndResults(ctx, year)
+	sessions, resultsMap, statusMap, err := s.getRaceSessionsAndResults(ctx, year)
 	if err != nil {
 		s.logger.Error("Failed to get sessions and results", zap.Error(err))
 		return nil, err
 	}
 
+	// 車手身分以當季各場次名單為準 (車號可能被重複使用、車手可能轉隊)
+	var scoredSessions []Session
+	for _, sess := range sessions {
+		if statusMap[sess.SessionKey] == RoundStatusComplete {
+			scoredSessions = append(scoredSessions, sess)
+		}
+	}
+	registry, err := NewDriverRegistryService(s.BaseService).BuildRegistry(ctx, year, scoredSessions)
+	if err != nil {
+		s.logger.Warn("Failed to build driver registry, falling back to session results", zap.Error(err))
+		registry = &DriverRegistry{Year: year}
+	}
+
+	if opts != nil {
+		sessions, resultsMap, err = applyWhatIf(year, sessions, resultsMap, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Locations: 拼接 Location + SessionName
 	locations := make([]string, len(sessions))
 	for i, sess := range sessions {
@@ -54,31 +72,67 @@ func (s *StandingsService) GetStandingsHistory(ctx context.Context, year int) (*
 	}
 	s.logger.Debug("Locations prepared", zap.Strings("locations", locations))
 
-	driverStandings := s.buildDriverPointsHistory(ctx, sessions, resultsMap)
+	driverStandings := s.buildDriverPointsHistory(registry, sessions, resultsMap)
+
+	// 每站資料狀態：缺漏的場次積分以 0 計算，必須明確標示
+	rounds := make([]RoundStatus, len(sessions))
+	var missing []int
+	for i, sess := range sessions {
+		status := statusMap[sess.SessionKey]
+		rounds[i] = RoundStatus{
+			Round:       i + 1,
+			SessionKey:  sess.SessionKey,
+			SessionName: sess.SessionName,
+			DateStart:   sess.DateStart,
+			Location:    locations[i],
+			Status:      status,
+			Retrying:    status == RoundStatusMissing && resultCache.isRetrying(sess.SessionKey),
+		}
+		if status == RoundStatusMissing {
+			missing = append(missing, i+1)
+		}
+	}
+	// 缺漏場次之後的累積積分與排名無法確定，各站資料只提供到第一個缺漏場次之前
+	scored := len(sessions)
+	if len(missing) > 0 {
+		s.logger.Warn("Standings are incomplete", zap.Int("year", year), zap.Ints("missing_rounds", missing))
+		scored = missing[0] - 1
+		for i := range driverStandings {
+			withholdAfter(&driverStandings[i], scored)
+		}
+		sort.SliceStable(driverStandings, func(i, j int) bool {
+			return finalPosition(driverStandings[i]) < finalPosition(driverStandings[j])
+		})
+	}
 
 	return &StandingsHistory{
 		Year:            year,
 		TotalRounds:     len(sessions),
 		Locations:       locations,
 		DriverStandings: driverStandings,
+		Rounds:          rounds,
+		ScoredRounds:    scored,
+		Complete:        len(missing) == 0,
+		MissingRounds:   missing,
+		WhatIf:          opts,
 	}, nil
 }
 
 // =======================
 // 車手積分整理
 // =======================
-func (s *StandingsService) buildDriverPointsHistory(ctx context.Context, events []Session, resultsMap map[int][]SessionResult) []DriverPointHistory {
+func (s *StandingsService) buildDriverPointsHistory(registry *DriverRegistry, events []Session, resultsMap map[int][]SessionResult) []DriverPointHistory {
 	driverPoints := make(map[int]*DriverPointHistory)
 
 	// 初始化車手
 	for _, results := range resultsMap {
 		for _, r := range results {
 			if _, ok := driverPoints[r.DriverNumber]; !ok {
-				// 從 cache / API 拿 driver info
-				info, err := s.getDriverInfo(ctx, r.DriverNumber)
-				if err != nil {
-					s.logger.Warn("Failed to fetch driver info", zap.Int("driver", r.DriverNumber), zap.Error(err))
-					continue
+				// 取該車手本季最後一次出場的身分與車隊
+				info, ok := registry.Latest(r.DriverNumber)
+				if !ok {
+					s.logger.Warn("Driver not found in registry", zap.Int("driver", r.DriverNumber), zap.Int("year", registry.Year))
+					info = Driver{DriverNumber: r.DriverNumber, FullName: r.FullName, Team: r.TeamName}
 				}
 
 				driverPoints[r.DriverNumber] = &DriverPointHistory{
@@ -89,22 +143,34 @@ func (s *StandingsService) buildDriverPointsHistory(ctx context.Context, events
 					Color:        info.Color,
 					TeamName:     info.Team,
 
-					RoundPoints:      make([]float64, 0, len(events)),
-					CumulativePoints: make([]float64, 0, len(events)),
-					Positions:        make([]int, 0, len(events)),
+					RoundPoints:           make([]float64, 0, len(events)),
+					RoundTeams:            make([]string, 0, len(events)),
+					CumulativePoints:      make([]float64, 0, len(events)),
+					RacePositions:         make([]int, 0, len(events)),
+					ChampionshipPositions: make([]int, 0, len(events)),
 				}
 			}
 		}
 	}
 
+	// 每位車手正賽各名次的次數，用於同分時的 countback
+	finishCounts := make(map[int][]int)
+
 	// 填寫每一場比賽的積分/排名
 	for _, event := range events {
 		results := resultsMap[event.SessionKey]
 
 		pointsMap := make(map[int]float64)
 		posMap := make(map[int]int)
+		teamMap := make(map[int]string)
 		for _, r := range results {
 			pointsMap[r.DriverNumber] = r.Points
+			// 車隊積分以該場代表的車隊計算，賽季中轉隊不會把積分帶到新車隊
+			if d, ok := registry.Lookup(event.SessionKey, r.DriverNumber); ok {
+				teamMap[r.DriverNumber] = d.Team
+			} else {
+				teamMap[r.DriverNumber] = r.TeamName
+			}
 			// 處理 Position 為 null 的情況，填 0 表示未完賽
 			if r.DNF || r.DNS || r.DSQ || r.Position == 0 {
 				posMap[r.DriverNumber] = -1 // -1 = dnf/dns/dsq
@@ -120,91 +186,113 @@ func (s *StandingsService) buildDriverPointsHistory(ctx context.Context, events
 			}
 			cum := prev + pointsMap[driverNum]
 			history.RoundPoints = append(history.RoundPoints, pointsMap[driverNum])
+			history.RoundTeams = append(history.RoundTeams, teamMap[driverNum])
 			history.CumulativePoints = append(history.CumulativePoints, cum)
-			history.Positions = append(history.Positions, posMap[driverNum])
+			history.RacePositions = append(history.RacePositions, posMap[driverNum])
+
+			// Sprint 名次不列入 countback
+			if pos := posMap[driverNum]; event.SessionName == "Race" && pos > 0 {
+				finishCounts[driverNum] = addFinish(finishCounts[driverNum], pos)
+			}
+		}
+
+		// 本站結束後的積分榜排名
+		totals := make(map[int]float64, len(driverPoints))
+		for driverNum, history := range driverPoints {
+			totals[driverNum] = history.CumulativePoints[len(history.CumulativePoints)-1]
+		}
+		for rank, driverNum := range rankByCountback(totals, finishCounts) {
+			history := driverPoints[driverNum]
+			history.ChampionshipPositions = append(history.ChampionshipPositions, rank+1)
 		}
 	}
 
-	// 最後依累積分排序
+	// 最後依最終積分榜排名排序
 	var result []DriverPointHistory
 	for _, h := range driverPoints {
 		result = append(result, *h)
 	}
 	sort.Slice(result, func(i, j int) bool {
-		pi := result[i].CumulativePoints[len(result[i].CumulativePoints)-1]
-		pj := result[j].CumulativePoints[len(result[j].CumulativePoints)-1]
-		return pi > pj
+		return finalPosition(result[i]) < finalPosition(result[j])
 	})
 
 	s.logger.Debug("Driver standings built", zap.Int("total_drivers", len(result)))
 	return result
 }
 
+// withholdAfter 只保留前 rounds 站的資料
+func withholdAfter(h *DriverPointHistory, rounds int) {
+	h.RoundPoints = h.RoundPoints[:min(rounds, len(h.RoundPoints))]
+	h.RoundTeams = h.RoundTeams[:min(rounds, len(h.RoundTeams))]
+	h.CumulativePoints = h.CumulativePoints[:min(rounds, len(h.CumulativePoints))]
+	h.RacePositions = h.RacePositions[:min(rounds, len(h.RacePositions))]
+	h.ChampionshipPositions = h.ChampionshipPositions[:min(rounds, len(h.ChampionshipPositions))]
+}
+
 // =======================
-// Driver Info 補齊機制
+// 積分榜排名 (FIA countback)
 // =======================
-func (s *StandingsService) preloadDrivers(ctx context.Context) error {
-	data, err := s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
-		return s.DS.GetLatestDrivers(ctx)
-	})
-	if err != nil {
-		return err
-	}
 
-	var drivers []Driver
-	if err := json.Unmarshal(data, &drivers); err != nil {
-		return err
+// addFinish 將一次完賽名次計入 counts (index = 名次-1)
+func addFinish(counts []int, position int) []int {
+	for len(counts) < position {
+		counts = append(counts, 0)
 	}
+	counts[position-1]++
+	return counts
+}
 
-	s.cacheMu.Lock()
-	defer s.cacheMu.Unlock()
-	for _, d := range drivers {
-		copy := d
-		s.driverCache[d.DriverNumber] = &copy
+// compareCountback 比較兩位車手的名次次數：冠軍次數多者優先，相同則比較亞軍次數，依此類推。
+// 回傳 <0 表示 a 排名較前，>0 表示 b 排名較前，0 表示完全相同。
+func compareCountback(a, b []int) int {
+	n := max(len(a), len(b))
+	for i := 0; i < n; i++ {
+		var ca, cb int
+		if i < len(a) {
+			ca = a[i]
+		}
+		if i < len(b) {
+			cb = b[i]
+		}
+		if ca != cb {
+			return cb - ca
+		}
 	}
-	s.logger.Info("Preloaded drivers", zap.Int("count", len(drivers)))
-	return nil
+	return 0
 }
 
-func (s *StandingsService) getDriverInfo(ctx context.Context, driverNumber int) (*Driver, error) {
-	// 先從 cache 讀
-	s.cacheMu.RLock()
-	if d, ok := s.driverCache[driverNumber]; ok && d.FullName != "" && d.Team != "" {
-		s.cacheMu.RUnlock()
-		return d, nil
+// rankByCountback 依累積積分排序，同分時套用 countback；
+// countback 仍無法分出高下時以車號排序，確保結果穩定。
+func rankByCountback(totals map[int]float64, finishCounts map[int][]int) []int {
+	drivers := make([]int, 0, len(totals))
+	for driverNum := range totals {
+		drivers = append(drivers, driverNum)
 	}
-	s.cacheMu.RUnlock()
-
-	// 打 API
-	data, err := s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
-		return s.DS.GetDriverInfo(ctx, driverNumber)
+	sort.Slice(drivers, func(i, j int) bool {
+		di, dj := drivers[i], drivers[j]
+		if totals[di] != totals[dj] {
+			return totals[di] > totals[dj]
+		}
+		if c := compareCountback(finishCounts[di], finishCounts[dj]); c != 0 {
+			return c < 0
+		}
+		return di < dj
 	})
-	if err != nil {
-		return nil, err
-	}
+	return drivers
+}
 
-	var drivers []Driver
-	if err := json.Unmarshal(data, &drivers); err != nil {
-		return nil, err
-	}
-	if len(drivers) == 0 {
-		return nil, fmt.Errorf("driver %d not found", driverNumber)
+// finalPosition 回傳車手最後一站後的積分榜排名
+func finalPosition(h DriverPointHistory) int {
+	if len(h.ChampionshipPositions) == 0 {
+		return math.MaxInt
 	}
-
-	d := drivers[len(drivers)-1]
-
-	// 更新 cache
-	s.cacheMu.Lock()
-	s.driverCache[driverNumber] = &d
-	s.cacheMu.Unlock()
-
-	return &d, nil
+	return h.ChampionshipPositions[len(h.ChampionshipPositions)-1]
 }
 
 // =======================
 // 取得指定年份的 Race/Sprint Session 與結果
 // =======================
-func (s *StandingsService) getRaceSessionsAndResults(ctx context.Context, year int) ([]Session, map[int][]SessionResult, error) {
+func (s *StandingsService) getRaceSessionsAndResults(ctx context.Context, year int) ([]Session, map[int][]SessionResult, map[int]RoundDataStatus, error) {
 	s.logger.Info("Fetching sessions for year", zap.Int("year", year))
 
 	data, err := s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
@@ -212,13 +300,13 @@ func (s *StandingsService) getRaceSessionsAndResults(ctx context.Context, year i
 	})
 	if err != nil {
 		s.logger.Error("Failed to fetch sessions", zap.Error(err))
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	var allSessions []Session
 	if err := json.Unmarshal(data, &allSessions); err != nil {
 		s.logger.Error("Failed to unmarshal sessions", zap.Error(err))
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	var events []Session
@@ -232,52 +320,192 @@ func (s *StandingsService) getRaceSessionsAndResults(ctx context.Context, year i
 	s.logger.Debug("Filtered and sorted sessions", zap.Int("total_sessions", len(events)))
 
 	resultsMap := make(map[int][]SessionResult)
+	statusMap := make(map[int]RoundDataStatus)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, 3)
+	now := time.Now()
 
 	for _, session := range events {
+		// 尚未開賽的場次不必抓取
+		if start, err := time.Parse(time.RFC3339, session.DateStart); err == nil && start.After(now) {
+			statusMap[session.SessionKey] = RoundStatusPending
+			continue
+		}
+
+		// 先用快取 (含背景重試補回的結果)
+		if results, ok := resultCache.get(session.SessionKey); ok {
+			resultsMap[session.SessionKey] = results
+			statusMap[session.SessionKey] = RoundStatusComplete
+			continue
+		}
+
 		wg.Add(1)
 		go func(session Session) {
 			defer wg.Done()
 
 			semaphore <- struct{}{}
-			s.mu.Lock()
-			<-s.rateLimiter.C
-			s.mu.Unlock()
-
-			var resultData []byte
+			var results []SessionResult
 			var err error
 			for i := 0; i < 3; i++ {
-				resultData, err = s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
-					return s.DS.GetResultBySession(ctx, session.SessionKey)
-				})
+				if err = s.throttle(ctx); err != nil {
+					break
+				}
+				results, err = s.fetchSessionResults(ctx, session.SessionKey)
 				if err == nil {
 					break
 				}
 				time.Sleep(time.Duration(i+1) * time.Second)
 			}
 			<-semaphore
+			if ctx.Err() != nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
 
 			if err != nil {
-				s.logger.Warn("Failed to fetch session results", zap.Int("session_key", session.SessionKey), zap.Error(err))
+				s.logger.Warn("Failed to fetch session results, retrying in background", zap.Int("session_key", session.SessionKey), zap.Error(err))
+				statusMap[session.SessionKey] = RoundStatusMissing
+				s.retryInBackground(session.SessionKey)
 				return
 			}
 
-			var results []SessionResult
-			if err := json.Unmarshal(resultData, &results); err != nil {
-				s.logger.Warn("Failed to unmarshal session results", zap.Int("session_key", session.SessionKey), zap.Error(err))
+			// 已開賽但官方尚未公布結果
+			if len(results) == 0 {
+				statusMap[session.SessionKey] = RoundStatusPending
 				return
 			}
 
-			mu.Lock()
+			resultCache.set(session.SessionKey, results)
 			resultsMap[session.SessionKey] = results
-			mu.Unlock()
+			statusMap[session.SessionKey] = RoundStatusComplete
 			s.logger.Debug("Fetched session results", zap.Int("session_key", session.SessionKey), zap.Int("num_results", len(results)))
 		}(session)
 	}
 
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
 	s.logger.Info("Finished fetching all session results", zap.Int("total_sessions", len(events)))
-	return events, resultsMap, nil
+	return events, resultsMap, statusMap, nil
+}
+
+func (s *StandingsService) fetchSessionResults(ctx context.Context, sessionKey int) ([]SessionResult, error) {
+	data, err := s.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetResultBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SessionResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("unmarshal session results failed: %w", err)
+	}
+	return results, nil
+}
+
+// =======================
+// 結果快取與背景重試
+// =======================
+
+const (
+	resultCacheTTL          = 10 * time.Minute
+	backgroundRetryAttempts = 5
+	backgroundRetryDelay    = 10 * time.Second
+	backgroundRetryTimeout  = 5 * time.Minute
+)
+
+// resultCache 跨請求共用，背景重試成功的結果會寫入這裡，下一次請求即可取得
+var resultCache = &sessionResultCache{
+	entries:  make(map[int]cachedResults),
+	retrying: make(map[int]bool),
+}
+
+type cachedResults struct {
+	results   []SessionResult
+	fetchedAt time.Time
+}
+
+type sessionResultCache struct {
+	mu       sync.RWMutex
+	entries  map[int]cachedResults
+	retrying map[int]bool
+}
+
+func (c *sessionResultCache) get(sessionKey int) ([]SessionResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[sessionKey]
+	if !ok || time.Since(entry.fetchedAt) > resultCacheTTL {
+		return nil, false
+	}
+	return entry.results, true
+}
+
+func (c *sessionResultCache) set(sessionKey int, results []SessionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[sessionKey] = cachedResults{results: results, fetchedAt: time.Now()}
+}
+
+// startRetry 標記開始重試，已在重試中則回傳 false
+func (c *sessionResultCache) startRetry(sessionKey int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.retrying[sessionKey] {
+		return false
+	}
+	c.retrying[sessionKey] = true
+	return true
+}
+
+func (c *sessionResultCache) finishRetry(sessionKey int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.retrying, sessionKey)
+}
+
+func (c *sessionResultCache) isRetrying(sessionKey int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.retrying[sessionKey]
+}
+
+// retryInBackground 在請求結束後持續重試抓取失敗的場次，同一場次同時只會有一個重試
+func (s *StandingsService) retryInBackground(sessionKey int) {
+	if !resultCache.startRetry(sessionKey) {
+		return
+	}
+
+	go func() {
+		defer resultCache.finishRetry(sessionKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundRetryTimeout)
+		defer cancel()
+
+		for attempt := 1; attempt <= backgroundRetryAttempts; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(attempt) * backgroundRetryDelay):
+			}
+
+			// 失敗通常是被 OpenF1 限速，重試同樣要排入共用的限速器
+			if s.throttle(ctx) != nil {
+				return
+			}
+			results, err := s.fetchSessionResults(ctx, sessionKey)
+			if err == nil && len(results) > 0 {
+				resultCache.set(sessionKey, results)
+				s.logger.Info("Background retry recovered session results", zap.Int("session_key", sessionKey), zap.Int("attempt", attempt))
+				return
+			}
+			s.logger.Warn("Background retry failed", zap.Int("session_key", sessionKey), zap.Int("attempt", attempt), zap.Error(err))
+		}
+		s.logger.Error("Giving up background retry", zap.Int("session_key", sessionKey))
+	}()
 }
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"math"
+	"sort"
+)
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// percentile 線性內插的百分位數，p 介於 0 ~ 1
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	pos := p * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// linearFit 最小平方法線性回歸 y = slope*x + intercept，點數不足或 x 全相同時 ok 為 false
+func linearFit(xs, ys []float64) (slope, intercept float64, ok bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, 0, false
+	}
+
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx float64
+	for i := range xs {
+		dx := xs[i] - mx
+		sxy += dx * (ys[i] - my)
+		sxx += dx * dx
+	}
+	if sxx == 0 || math.IsNaN(sxx) {
+		return 0, 0, false
+	}
+	slope = sxy / sxx
+	intercept = my - slope*mx
+	return slope, intercept, true
+}
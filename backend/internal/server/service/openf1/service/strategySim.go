@@ -0,0 +1,534 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 模擬使用的乾胎配方
+var simCompounds = []string{"SOFT", "MEDIUM", "HARD"}
+
+// 資料來源
+const (
+	SimSourceInput   = "input"
+	SimSourceArchive = "archive"
+	SimSourceDefault = "default"
+)
+
+const (
+	DefaultSimMinStintLaps       = 5
+	DefaultSimMaxStops           = 3
+	DefaultSimSimulations        = 1000
+	DefaultSimHistoryYears       = 3
+	DefaultSafetyCarLaps         = 4
+	DefaultSafetyCarPitLossRatio = 0.5 // SC 期間進站只損失一半時間
+	DefaultSafetyCarLapFactor    = 1.4 // SC 圈約為正常圈速的 1.4 倍
+	MaxSimTotalLaps              = 100
+	MaxSimHistoryYears           = 10
+)
+
+// ErrInvalidStrategySimOptions 模擬設定不合法 (例如最短 stint 乘上段數超過比賽圈數)
+var ErrInvalidStrategySimOptions = errors.New("invalid strategy simulation options")
+
+// defaultCompoundModels 歷史資料沒有該配方時使用，偏移量相對於中性胎的新胎圈速
+var defaultCompoundModels = map[string]CompoundModel{
+	"SOFT":   {Compound: "SOFT", Offset: -0.5, Degradation: 0.10},
+	"MEDIUM": {Compound: "MEDIUM", Offset: 0, Degradation: 0.06},
+	"HARD":   {Compound: "HARD", Offset: 0.5, Degradation: 0.035},
+}
+
+// StrategySimOptions 模擬輸入；數值為 0 (SC 機率為 nil) 時以歷史資料推算
+type StrategySimOptions struct {
+	DriverNumber         int             `json:"driver_number"` // 0 表示使用全場中位數
+	BasePace             float64         `json:"base_pace"`     // 中性胎新胎、空油箱的圈速 (秒)
+	PitLoss              float64         `json:"pit_loss"`      // 綠旗下一次進站比留在賽道上多花的時間 (秒)
+	TotalLaps            int             `json:"total_laps"`
+	FuelCorrection       float64         `json:"fuel_correction"`
+	Compounds            []CompoundModel `json:"compounds"` // 覆寫個別配方
+	RequireTwoCompounds  bool            `json:"require_two_compounds"`
+	MinStintLaps         int             `json:"min_stint_laps"`
+	MaxStops             int             `json:"max_stops"`
+	SafetyCarProbability *float64        `json:"safety_car_probability"`
+	SafetyCarLaps        int             `json:"safety_car_laps"`
+	Simulations          int             `json:"simulations"`
+	Seed                 int64           `json:"seed"`
+	HistoryYears         int             `json:"history_years"` // 推算 SC 機率時往前查詢的年數
+}
+
+func DefaultStrategySimOptions() StrategySimOptions {
+	return StrategySimOptions{
+		FuelCorrection:      DefaultRacePaceOptions().FuelCorrection,
+		RequireTwoCompounds: true,
+		MinStintLaps:        DefaultSimMinStintLaps,
+		MaxStops:            DefaultSimMaxStops,
+		Simulations:         DefaultSimSimulations,
+		Seed:                1,
+		HistoryYears:        DefaultSimHistoryYears,
+	}
+}
+
+type StrategySimService struct {
+	*BaseService
+}
+
+func NewStrategySimService(base *BaseService) *StrategySimService {
+	return &StrategySimService{BaseService: base}
+}
+
+// =======================
+// 主入口: 進站策略模擬
+// =======================
+
+// GetStrategySimulation 以指定正賽的歷史資料作為預設輸入，評估所有合法的 1 ~ 3 停策略並以蒙地卡羅模擬 SC
+func (s *StrategySimService) GetStrategySimulation(ctx context.Context, sessionKey int, opts StrategySimOptions) (*StrategySimulation, error) {
+	inputs, err := s.resolveInputs(ctx, sessionKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	sim := simulateStrategies(inputs, opts)
+	sim.SessionKey = sessionKey
+	return sim, nil
+}
+
+// resolveInputs 使用者輸入優先，其次為該場正賽的配速、進站與 race control 資料，最後為預設值
+func (s *StrategySimService) resolveInputs(ctx context.Context, sessionKey int, opts StrategySimOptions) (StrategySimInputs, error) {
+	inputs := StrategySimInputs{
+		TotalLaps:      opts.TotalLaps,
+		BasePace:       opts.BasePace,
+		PitLoss:        opts.PitLoss,
+		FuelCorrection: opts.FuelCorrection,
+		SafetyCarLaps:  opts.SafetyCarLaps,
+		Sources:        map[string]string{},
+	}
+
+	paceOpts := DefaultRacePaceOptions()
+	paceOpts.FuelCorrection = opts.FuelCorrection
+	if err := s.throttle(ctx); err != nil {
+		return inputs, err
+	}
+	pace, err := NewRacePaceService(s.BaseService).GetSessionRacePace(ctx, sessionKey, paceOpts)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch race pace, using defaults", zap.Int("session_key", sessionKey), zap.Error(err))
+		pace = &SessionRacePace{}
+	}
+
+	inputs.Sources["total_laps"] = SimSourceInput
+	if inputs.TotalLaps == 0 {
+		inputs.TotalLaps = pace.TotalLaps
+		inputs.Sources["total_laps"] = SimSourceArchive
+	}
+	if inputs.TotalLaps <= 0 {
+		return inputs, fmt.Errorf("race length unavailable for session %d", sessionKey)
+	}
+	if opts.MinStintLaps*(opts.MaxStops+1) > inputs.TotalLaps {
+		return inputs, fmt.Errorf("%w: %d stops with at least %d laps per stint exceed %d race laps",
+			ErrInvalidStrategySimOptions, opts.MaxStops, opts.MinStintLaps, inputs.TotalLaps)
+	}
+
+	compoundPace := archiveCompoundPace(pace.Drivers, opts.DriverNumber)
+	base, models := resolveCompoundModels(compoundPace, opts.Compounds)
+	inputs.Compounds = models
+	inputs.Sources["base_pace"] = SimSourceInput
+	if inputs.BasePace == 0 {
+		inputs.BasePace = base
+		inputs.Sources["base_pace"] = SimSourceArchive
+	}
+	if inputs.BasePace <= 0 {
+		return inputs, fmt.Errorf("base pace unavailable for session %d", sessionKey)
+	}
+
+	if err := s.throttle(ctx); err != nil {
+		return inputs, err
+	}
+	lapHistory, _, err := NewLapService(s.BaseService).GetMarkedLapHistory(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch lap history", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+	inputs.Sources["pit_loss"] = SimSourceInput
+	if inputs.PitLoss == 0 {
+		losses := archivePitLoss(lapHistory)
+		if len(losses) == 0 || median(losses) <= 0 {
+			return inputs, fmt.Errorf("pit loss unavailable for session %d", sessionKey)
+		}
+		inputs.PitLoss = median(losses)
+		inputs.Sources["pit_loss"] = SimSourceArchive
+	}
+
+	inputs.Sources["safety_car"] = SimSourceInput
+	if opts.SafetyCarProbability != nil {
+		inputs.SafetyCarProbability = *opts.SafetyCarProbability
+	} else {
+		meetingKey := 0
+		for _, laps := range lapHistory {
+			if len(laps) > 0 {
+				meetingKey = laps[0].MeetingKey
+				break
+			}
+		}
+		history, err := s.safetyCarHistory(ctx, sessionKey, meetingKey, opts.HistoryYears)
+		if err != nil {
+			return inputs, err
+		}
+		inputs.SafetyCarRaces = history.races
+		inputs.Sources["safety_car"] = SimSourceDefault
+		if history.races > 0 {
+			inputs.SafetyCarProbability = float64(history.withSafetyCar) / float64(history.races)
+			inputs.Sources["safety_car"] = SimSourceArchive
+		}
+		if inputs.SafetyCarLaps == 0 && history.safetyCars > 0 {
+			inputs.SafetyCarLaps = max(1, int(float64(history.safetyCarLaps)/float64(history.safetyCars)+0.5))
+		}
+	}
+	if inputs.SafetyCarLaps == 0 {
+		inputs.SafetyCarLaps = DefaultSafetyCarLaps
+	}
+	return inputs, nil
+}
+
+// =======================
+// 歷史資料
+// =======================
+
+// archiveCompoundPace 各配方新胎圈速 (已擬合 stint 的截距中位數) 與衰退 (已擬合斜率中位數)；
+// 指定車手時只用該車手的資料
+func archiveCompoundPace(drivers []DriverRacePace, driverNumber int) map[string]CompoundModel {
+	paces := make(map[string][]float64)
+	degs := make(map[string][]float64)
+	for _, d := range drivers {
+		if driverNumber != 0 && d.DriverNumber != driverNumber {
+			continue
+		}
+		for _, st := range d.Stints {
+			if st.Fitted {
+				compound := strings.ToUpper(st.Compound)
+				paces[compound] = append(paces[compound], st.PaceAtNewTyre)
+			}
+		}
+		for _, cp := range d.Compounds {
+			if cp.DegradationFitted {
+				degs[cp.Compound] = append(degs[cp.Compound], cp.Degradation)
+			}
+		}
+	}
+
+	result := make(map[string]CompoundModel)
+	for _, compound := range simCompounds {
+		if len(paces[compound]) == 0 {
+			continue
+		}
+		m := CompoundModel{Compound: compound, Offset: median(paces[compound]), Source: SimSourceArchive}
+		if len(degs[compound]) > 0 {
+			m.Degradation = max(0, median(degs[compound]))
+		} else {
+			m.Degradation = defaultCompoundModels[compound].Degradation
+		}
+		result[compound] = m
+	}
+	return result
+}
+
+// archivePitLoss 綠旗下每次進站的損失：進站圈加出站圈減去該車手兩圈正常圈速 (中位數)。
+// OpenF1 的 pit_duration 只是維修區通過時間，沒有扣掉在賽道上跑同一段的時間，不能直接當作損失；
+// 中立化期間的進站損失較小，由 SC 模擬另外處理，這裡排除
+func archivePitLoss(lapHistory map[int][]LapRecord) []float64 {
+	var losses []float64
+	for _, laps := range lapHistory {
+		pitLaps := make(map[int]bool)
+		for _, lap := range laps {
+			if lap.IsPitOutLap {
+				pitLaps[lap.LapNumber] = true
+				pitLaps[lap.LapNumber-1] = true
+			}
+		}
+		var clean []float64
+		for _, lap := range laps {
+			if lap.LapNumber > 1 && lap.LapDuration > 0 && !pitLaps[lap.LapNumber] && lap.Neutralised == "" {
+				clean = append(clean, lap.LapDuration)
+			}
+		}
+		if len(clean) == 0 {
+			continue
+		}
+		reference := median(clean)
+
+		for i := 1; i < len(laps); i++ {
+			in, out := laps[i-1], laps[i]
+			if !out.IsPitOutLap || out.LapNumber != in.LapNumber+1 ||
+				in.LapDuration <= 0 || out.LapDuration <= 0 || in.Neutralised != "" || out.Neutralised != "" {
+				continue
+			}
+			// 負值 (維修區短或進站圈特別快) 也保留，避免中位數偏高
+			losses = append(losses, in.LapDuration+out.LapDuration-2*reference)
+		}
+	}
+	return losses
+}
+
+// resolveCompoundModels 把歷史新胎圈速換算為相對中性胎的偏移量。沒有中性胎資料時，
+// 以其他配方扣掉預設偏移量的平均估計基準圈速；使用者輸入的配方直接覆寫
+func resolveCompoundModels(archive map[string]CompoundModel, overrides []CompoundModel) (float64, []CompoundModel) {
+	base := 0.0
+	if m, ok := archive["MEDIUM"]; ok {
+		base = m.Offset
+	} else if len(archive) > 0 {
+		var estimates []float64
+		for compound, m := range archive {
+			estimates = append(estimates, m.Offset-defaultCompoundModels[compound].Offset)
+		}
+		base = mean(estimates)
+	}
+
+	models := make([]CompoundModel, 0, len(simCompounds))
+	for _, compound := range simCompounds {
+		m, ok := archive[compound]
+		if ok {
+			m.Offset -= base
+		} else {
+			m = defaultCompoundModels[compound]
+			m.Source = SimSourceDefault
+		}
+		if i := slices.IndexFunc(overrides, func(o CompoundModel) bool { return strings.EqualFold(o.Compound, compound) }); i >= 0 {
+			m = overrides[i]
+			m.Compound = compound
+			m.Source = SimSourceInput
+		}
+		models = append(models, m)
+	}
+	return base, models
+}
+
+type safetyCarHistory struct {
+	races, withSafetyCar, safetyCars, safetyCarLaps int
+}
+
+// safetyCarHistory 統計前幾年同一賽道正賽的 SC 次數與長度；不含本場，避免模擬的比賽本身的結果影響先驗機率
+func (s *StrategySimService) safetyCarHistory(ctx context.Context, sessionKey, meetingKey, years int) (safetyCarHistory, error) {
+	var history safetyCarHistory
+	var raceKeys []int
+
+	if meetingKey > 0 && years > 0 {
+		if err := s.throttle(ctx); err != nil {
+			return history, err
+		}
+		sessions, err := fetchRecords[Session](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+			return s.DS.GetSessionByMeeting(ctx, meetingKey)
+		})
+		if err != nil {
+			s.Logger.Warn("Failed to fetch meeting sessions", zap.Int("meeting_key", meetingKey), zap.Error(err))
+		}
+		if i := slices.IndexFunc(sessions, func(sess Session) bool { return sess.SessionKey == sessionKey }); i >= 0 {
+			current := sessions[i]
+			for year := current.Year - years; year < current.Year; year++ {
+				if err := s.throttle(ctx); err != nil {
+					return history, err
+				}
+				past, err := s.getPastSessions(ctx, year, current.SessionName)
+				if err != nil {
+					s.Logger.Warn("Failed to fetch past sessions", zap.Int("year", year), zap.Error(err))
+					continue
+				}
+				for _, sess := range past {
+					if sess.CircuitKey == current.CircuitKey {
+						raceKeys = append(raceKeys, sess.SessionKey)
+					}
+				}
+			}
+		}
+	}
+
+	for _, key := range raceKeys {
+		if err := s.throttle(ctx); err != nil {
+			return history, err
+		}
+		records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+			return s.DS.GetRaceControlBySession(ctx, key)
+		})
+		if err != nil {
+			s.Logger.Warn("Failed to fetch race control for safety car history", zap.Int("session_key", key), zap.Error(err))
+			continue
+		}
+		history.races++
+		hadSafetyCar := false
+		for _, p := range ExtractNeutralisations(ParseRaceControlEvents(records)) {
+			if p.Type != NeutralisationSC {
+				continue
+			}
+			hadSafetyCar = true
+			history.safetyCars++
+			history.safetyCarLaps += p.EndLap - p.StartLap + 1
+		}
+		if hadSafetyCar {
+			history.withSafetyCar++
+		}
+	}
+	return history, nil
+}
+
+// =======================
+// 模擬
+// =======================
+
+// simulateStrategies 先以解析解評估每個配方順序的所有進站圈組合，取各順序最快者；
+// 再以相同的隨機 SC 情境比較這些候選策略。SC 期間的進站只損失部分時間，策略不因 SC 臨時調整。
+func simulateStrategies(inputs StrategySimInputs, opts StrategySimOptions) *StrategySimulation {
+	inputs.RequireTwoCompounds = opts.RequireTwoCompounds
+	inputs.MinStintLaps = max(1, opts.MinStintLaps)
+	inputs.MaxStops = min(max(1, opts.MaxStops), DefaultSimMaxStops)
+	inputs.Simulations = max(0, opts.Simulations)
+
+	models := make(map[string]CompoundModel, len(inputs.Compounds))
+	for _, m := range inputs.Compounds {
+		models[m.Compound] = m
+	}
+
+	n := inputs.TotalLaps
+	// 燃油影響與策略無關：第 lap 圈多帶 (n - lap) 圈的油
+	fuelTime := inputs.FuelCorrection * float64(n*(n-1)) / 2
+	stintTime := func(compound string, laps int) float64 {
+		m := models[compound]
+		return float64(laps)*(inputs.BasePace+m.Offset) + m.Degradation*float64(laps*(laps-1))/2
+	}
+
+	sim := &StrategySimulation{Inputs: inputs, Plans: []StrategyPlan{}}
+	for stops := 1; stops <= inputs.MaxStops; stops++ {
+		for _, sequence := range compoundSequences(inputs.Compounds, stops+1, inputs.RequireTwoCompounds) {
+			best := StrategyPlan{}
+			found := false
+			forEachStintSplit(n, stops+1, inputs.MinStintLaps, func(lengths []int) {
+				sim.Evaluated++
+				total := fuelTime + float64(stops)*inputs.PitLoss
+				for i, l := range lengths {
+					total += stintTime(sequence[i], l)
+				}
+				if !found || total < best.TotalTime {
+					found = true
+					best = StrategyPlan{Stops: stops, Compounds: sequence, PitLaps: pitLapsFor(lengths), TotalTime: total}
+				}
+			})
+			if found {
+				sim.Plans = append(sim.Plans, best)
+			}
+		}
+	}
+	if len(sim.Plans) == 0 {
+		return sim
+	}
+
+	sort.SliceStable(sim.Plans, func(i, j int) bool { return sim.Plans[i].TotalTime < sim.Plans[j].TotalTime })
+	for i := range sim.Plans {
+		sim.Plans[i].GapToOptimal = sim.Plans[i].TotalTime - sim.Plans[0].TotalTime
+	}
+	optimal := sim.Plans[0]
+	sim.Optimal = &optimal
+
+	if inputs.Simulations > 0 && inputs.SafetyCarProbability > 0 {
+		runSafetyCarSimulations(sim, inputs, opts.Seed)
+	}
+	return sim
+}
+
+// runSafetyCarSimulations 每次模擬以機率決定是否出現一次 SC，開始圈平均分布於第 2 圈至倒數第 2 圈
+func runSafetyCarSimulations(sim *StrategySimulation, inputs StrategySimInputs, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+	n := inputs.TotalLaps
+	saving := inputs.PitLoss * (1 - DefaultSafetyCarPitLossRatio)
+	scLapCost := inputs.BasePace * (DefaultSafetyCarLapFactor - 1)
+
+	totals := make([]float64, len(sim.Plans))
+	wins := make([]int, len(sim.Plans))
+	for i := 0; i < inputs.Simulations; i++ {
+		start, end := 0, -1
+		if n > 3 && rng.Float64() < inputs.SafetyCarProbability {
+			start = 2 + rng.Intn(n-3)
+			end = min(n-1, start+inputs.SafetyCarLaps-1)
+		}
+
+		bestIdx, bestTime := -1, 0.0
+		for p, plan := range sim.Plans {
+			t := plan.TotalTime
+			if end >= start {
+				t += float64(end-start+1) * scLapCost
+				for _, lap := range plan.PitLaps {
+					if lap >= start && lap <= end {
+						t -= saving
+					}
+				}
+			}
+			totals[p] += t
+			if bestIdx < 0 || t < bestTime {
+				bestIdx, bestTime = p, t
+			}
+		}
+		wins[bestIdx]++
+	}
+
+	var bestExpected *StrategyPlan
+	for p := range sim.Plans {
+		plan := &sim.Plans[p]
+		plan.ExpectedTime = totals[p] / float64(inputs.Simulations)
+		plan.WinProbability = float64(wins[p]) / float64(inputs.Simulations)
+		if bestExpected == nil || plan.ExpectedTime < bestExpected.ExpectedTime {
+			bestExpected = plan
+		}
+	}
+	optimal := *bestExpected
+	sim.OptimalWithSafetyCar = &optimal
+}
+
+// compoundSequences 列出長度為 count 的所有配方順序；規定須使用兩種配方時排除單一配方
+func compoundSequences(models []CompoundModel, count int, requireTwo bool) [][]string {
+	var sequences [][]string
+	var build func(prefix []string)
+	build = func(prefix []string) {
+		if len(prefix) == count {
+			if requireTwo && !slices.ContainsFunc(prefix, func(c string) bool { return c != prefix[0] }) {
+				return
+			}
+			sequences = append(sequences, append([]string(nil), prefix...))
+			return
+		}
+		for _, m := range models {
+			build(append(prefix, m.Compound))
+		}
+	}
+	build(make([]string, 0, count))
+	return sequences
+}
+
+// forEachStintSplit 列舉把 total 圈分成 stints 段、每段至少 minLaps 圈的所有組合
+func forEachStintSplit(total, stints, minLaps int, fn func(lengths []int)) {
+	lengths := make([]int, stints)
+	var split func(i, remaining int)
+	split = func(i, remaining int) {
+		if i == stints-1 {
+			if remaining >= minLaps {
+				lengths[i] = remaining
+				fn(lengths)
+			}
+			return
+		}
+		for l := minLaps; l <= remaining-minLaps*(stints-1-i); l++ {
+			lengths[i] = l
+			split(i+1, remaining-l)
+		}
+	}
+	split(0, total)
+}
+
+// pitLapsFor 各段長度換算為進站圈 (該 stint 的最後一圈)
+func pitLapsFor(lengths []int) []int {
+	laps := make([]int, 0, len(lengths)-1)
+	lap := 0
+	for _, l := range lengths[:len(lengths)-1] {
+		lap += l
+		laps = append(laps, lap)
+	}
+	return laps
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestForEachStintSplit(t *testing.T) {
+	tests := []struct {
+		total, stints, minLaps int
+		want                   int
+	}{
+		{total: 10, stints: 2, minLaps: 3, want: 5},
+		{total: 10, stints: 3, minLaps: 3, want: 3},
+		{total: 9, stints: 3, minLaps: 3, want: 1},
+		{total: 5, stints: 2, minLaps: 3, want: 0},
+		{total: 20, stints: 3, minLaps: 5, want: 21},
+	}
+	for _, tt := range tests {
+		count := 0
+		forEachStintSplit(tt.total, tt.stints, tt.minLaps, func(lengths []int) {
+			count++
+			sum := 0
+			for _, l := range lengths {
+				if l < tt.minLaps {
+					t.Errorf("%d laps / %d stints: stint of %d laps below minimum %d", tt.total, tt.stints, l, tt.minLaps)
+				}
+				sum += l
+			}
+			if sum != tt.total {
+				t.Errorf("%d laps / %d stints: split %v sums to %d", tt.total, tt.stints, lengths, sum)
+			}
+		})
+		if count != tt.want {
+			t.Errorf("%d laps / %d stints / min %d: %d splits, want %d", tt.total, tt.stints, tt.minLaps, count, tt.want)
+		}
+	}
+}
+
+func TestSimulateStrategies(t *testing.T) {
+	inputs := StrategySimInputs{
+		TotalLaps:     20,
+		BasePace:      90,
+		PitLoss:       20,
+		SafetyCarLaps: 4,
+		Compounds:     []CompoundModel{defaultCompoundModels["SOFT"], defaultCompoundModels["MEDIUM"], defaultCompoundModels["HARD"]},
+	}
+	opts := StrategySimOptions{RequireTwoCompounds: true, MinStintLaps: 5, MaxStops: 2, Simulations: 500, Seed: 1}
+
+	tests := []struct {
+		name          string
+		probability   float64
+		wantSCOptimal *StrategyPlan
+		wantWins      map[int]float64 // 策略排名 → 勝出比例
+	}{
+		{name: "no safety car", probability: 0},
+		{
+			name:          "seeded safety car",
+			probability:   0.6,
+			wantSCOptimal: &StrategyPlan{Stops: 1, Compounds: []string{"MEDIUM", "SOFT"}, PitLaps: []int{9}},
+			wantWins:      map[int]float64{0: 0.82, 1: 0.08, 2: 0.046, 3: 0.054},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := inputs
+			in.SafetyCarProbability = tt.probability
+			sim := simulateStrategies(in, opts)
+
+			// 1 停 6 種配方順序 × 11 種分段，2 停 24 種 × 21 種
+			if sim.Evaluated != 570 || len(sim.Plans) != 30 {
+				t.Fatalf("evaluated %d strategies into %d plans, want 570 / 30", sim.Evaluated, len(sim.Plans))
+			}
+			// SOFT 11 圈 (990 秒) + MEDIUM 9 圈 (812.16 秒) + 一次進站 20 秒
+			opt := sim.Optimal
+			if opt == nil || !reflect.DeepEqual(opt.Compounds, []string{"SOFT", "MEDIUM"}) || !reflect.DeepEqual(opt.PitLaps, []int{11}) ||
+				math.Abs(opt.TotalTime-1822.16) > 1e-6 {
+				t.Fatalf("optimal = %+v, want SOFT-MEDIUM pitting on lap 11 in 1822.16s", opt)
+			}
+
+			if tt.wantSCOptimal == nil {
+				if sim.OptimalWithSafetyCar != nil {
+					t.Errorf("optimal with safety car = %+v, want none", sim.OptimalWithSafetyCar)
+				}
+				return
+			}
+			got := sim.OptimalWithSafetyCar
+			if got == nil || got.Stops != tt.wantSCOptimal.Stops || !reflect.DeepEqual(got.Compounds, tt.wantSCOptimal.Compounds) ||
+				!reflect.DeepEqual(got.PitLaps, tt.wantSCOptimal.PitLaps) {
+				t.Fatalf("optimal with safety car = %+v, want %+v", got, tt.wantSCOptimal)
+			}
+
+			total := 0.0
+			for i, plan := range sim.Plans {
+				total += plan.WinProbability
+				if want, ok := tt.wantWins[i]; ok && math.Abs(plan.WinProbability-want) > 1e-9 {
+					t.Errorf("plan %d win probability = %v, want %v", i, plan.WinProbability, want)
+				}
+				if plan.ExpectedTime < plan.TotalTime {
+					t.Errorf("plan %d expected time %v below green-flag time %v", i, plan.ExpectedTime, plan.TotalTime)
+				}
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Errorf("win probabilities sum to %v, want 1", total)
+			}
+			if again := simulateStrategies(in, opts); !reflect.DeepEqual(again, sim) {
+				t.Errorf("same seed produced a different simulation")
+			}
+		})
+	}
+}
@@ -54,6 +54,11 @@ func (t *TelemetryService) GetLapCarData(ctx context.Context, sessionKey, driver
 		return nil, fmt.Errorf("lap %d not found", lapNumber)
 	}
 
+	return t.getLapCarDataInWindow(ctx, sessionKey, driverNum, lapNumber, startTime, endTime)
+}
+
+// getLapCarDataInWindow 以已知的圈起訖時間撈車輛資料，省去重新抓取 laps
+func (t *TelemetryService) getLapCarDataInWindow(ctx context.Context, sessionKey, driverNum, lapNumber int, startTime, endTime string) (*LapData, error) {
 	// 撈車輛資料，用 startTime ~ endTime（如果有）
 	carJSON, err := t.FetchJSON(ctx, func(ctx context.Context) ([]byte, error) {
 		return t.DS.GetCarDataByLap(
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// 距離網格間隔 (公尺)；最小值限制單圈的點數，避免極小間隔耗盡記憶體
+const (
+	DefaultCompareStep = 10.0
+	MinCompareStep     = 0.5
+)
+
+// ErrInvalidCompareStep 距離網格間隔不合法
+var ErrInvalidCompareStep = errors.New("invalid distance step")
+
+// TelemetryLapRef 指定要比較的 (session, 車手, 圈)
+type TelemetryLapRef struct {
+	SessionKey   int `json:"session_key"`
+	DriverNumber int `json:"driver_number"`
+	LapNumber    int `json:"lap_number"`
+}
+
+// =======================
+// 主入口: 雙車手遙測比較
+// =======================
+
+// CompareLaps 將兩圈遙測對齊到參考圈的距離網格，並計算累積時間差。
+// Delta 為 compare 減去 reference 的時間，正值表示 compare 較慢。
+func (t *TelemetryService) CompareLaps(ctx context.Context, reference, compare TelemetryLapRef, step float64) (*TelemetryComparison, error) {
+	if math.IsNaN(step) || math.IsInf(step, 0) || step < MinCompareStep {
+		return nil, fmt.Errorf("%w: %v (minimum %v)", ErrInvalidCompareStep, step, MinCompareStep)
+	}
+
+	refTrace, err := t.loadLapTrace(ctx, reference)
+	if err != nil {
+		return nil, fmt.Errorf("reference lap: %w", err)
+	}
+	cmpTrace, err := t.loadLapTrace(ctx, compare)
+	if err != nil {
+		return nil, fmt.Errorf("compare lap: %w", err)
+	}
+
+	if refTrace.length() <= 0 || cmpTrace.length() <= 0 {
+		return nil, fmt.Errorf("lap telemetry has no distance")
+	}
+
+	// 各圈積分距離會有 1 ~ 2% 差異，把比較圈縮放到參考圈長度，兩圈都完整對齊到終點
+	length := refTrace.length()
+	scale := length / cmpTrace.length()
+	points := int(length/step) + 1
+
+	result := &TelemetryComparison{
+		Reference: ComparedLap{TelemetryLapRef: reference, Distance: refTrace.length(), Duration: refTrace.samples[len(refTrace.samples)-1].time},
+		Compare:   ComparedLap{TelemetryLapRef: compare, Distance: cmpTrace.length(), Duration: cmpTrace.samples[len(cmpTrace.samples)-1].time},
+		Step:      step,
+		Distance:  make([]float64, points),
+		Delta:     make([]float64, points),
+	}
+	result.Reference.Channels = newAlignedChannels(points)
+	result.Compare.Channels = newAlignedChannels(points)
+
+	for i := 0; i < points; i++ {
+		d := float64(i) * step
+		ref := refTrace.atDistance(d)
+		cmp := cmpTrace.atDistance(d / scale)
+
+		result.Distance[i] = d
+		result.Reference.Channels.set(i, ref)
+		result.Compare.Channels.set(i, cmp)
+		result.Delta[i] = cmp.time - ref.time
+	}
+
+	return result, nil
+}
+
+func (t *TelemetryService) loadLapTrace(ctx context.Context, ref TelemetryLapRef) (*lapTrace, error) {
+	lap, err := t.GetLapCarData(ctx, ref.SessionKey, ref.DriverNumber, ref.LapNumber)
+	if err != nil {
+		return nil, err
+	}
+	return buildLapTrace(lap)
+}
+
+func newAlignedChannels(points int) AlignedChannels {
+	return AlignedChannels{
+		Time:     make([]float64, points),
+		Speed:    make([]int, points),
+		Throttle: make([]int, points),
+		Brake:    make([]int, points),
+		Gear:     make([]int, points),
+		DRS:      make([]int, points),
+	}
+}
+
+func (c *AlignedChannels) set(i int, s traceSample) {
+	c.Time[i] = s.time
+	c.Speed[i] = s.data.Speed
+	c.Throttle[i] = s.data.Throttle
+	c.Brake[i] = s.data.Brake
+	c.Gear[i] = s.data.NGear
+	c.DRS[i] = s.data.DRS
+}
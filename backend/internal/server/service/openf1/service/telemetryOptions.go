@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// 可選擇的遙測通道
+const (
+	ChannelSpeed    = "speed"
+	ChannelRPM      = "rpm"
+	ChannelGear     = "n_gear"
+	ChannelThrottle = "throttle"
+	ChannelBrake    = "brake"
+	ChannelDRS      = "drs"
+)
+
+var allTelemetryChannels = []string{ChannelSpeed, ChannelRPM, ChannelGear, ChannelThrottle, ChannelBrake, ChannelDRS}
+
+// maxResampleHz 重新取樣上限；OpenF1 car_data 原始頻率約 3.7 Hz
+const maxResampleHz = 50
+
+// ErrInvalidTelemetryOptions 遙測查詢參數不合法
+var ErrInvalidTelemetryOptions = errors.New("invalid telemetry options")
+
+// TelemetryOptions 遙測輸出設定；零值表示原始樣本、全部通道、ISO 時間
+type TelemetryOptions struct {
+	Points     int      // LTTB 降採樣後的點數，0 表示不降採樣
+	Hz         float64  // 固定頻率重新取樣，0 表示使用原始樣本
+	Channels   []string // 輸出的通道，空白表示全部
+	TimeOffset bool     // 以距圈起點秒數取代 ISO 時間
+}
+
+func (o TelemetryOptions) validate() error {
+	if o.Points != 0 && o.Points < 3 {
+		return fmt.Errorf("%w: points must be at least 3", ErrInvalidTelemetryOptions)
+	}
+	if o.Hz < 0 || o.Hz > maxResampleHz {
+		return fmt.Errorf("%w: hz must be between 0 and %d", ErrInvalidTelemetryOptions, maxResampleHz)
+	}
+	for _, ch := range o.Channels {
+		if !slices.Contains(allTelemetryChannels, ch) {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidTelemetryOptions, ch)
+		}
+	}
+	return nil
+}
+
+func (o TelemetryOptions) wants(channel string) bool {
+	return len(o.Channels) == 0 || slices.Contains(o.Channels, channel)
+}
+
+// =======================
+// 主入口: 可調整的單圈遙測
+// =======================
+
+// GetLapTelemetry 依設定重新取樣、降採樣並只輸出指定通道，回傳欄位式資料。
+// 同時指定 Hz 與 Points 時先重新取樣再降採樣。
+func (t *TelemetryService) GetLapTelemetry(ctx context.Context, sessionKey, driverNum, lapNumber int, opts TelemetryOptions) (*LapTelemetry, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	lap, err := t.GetLapCarData(ctx, sessionKey, driverNum, lapNumber)
+	if err != nil {
+		return nil, err
+	}
+	trace, err := buildLapTrace(lap)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := trace.samples
+	if opts.Hz > 0 {
+		samples = resampleByTime(trace, opts.Hz)
+	}
+	if opts.Points > 0 {
+		samples = downsampleLTTB(samples, opts.Points, lttbSeries(opts))
+	}
+
+	start, _ := parseSampleTime(lap.StartTime)
+	return buildLapTelemetry(lap, samples, start, opts), nil
+}
+
+// resampleByTime 以固定頻率在圈內時間軸上插值
+func resampleByTime(trace *lapTrace, hz float64) []traceSample {
+	step := 1 / hz
+	end := trace.samples[len(trace.samples)-1].time
+	n := int(end/step) + 1
+
+	out := make([]traceSample, 0, n)
+	for i := 0; i < n; i++ {
+		s := trace.atTime(float64(i) * step)
+		s.data.Date = ""
+		out = append(out, s)
+	}
+	return out
+}
+
+// lttbSeries LTTB 依據的通道：優先使用指定通道中的連續量，預設為速度
+func lttbSeries(opts TelemetryOptions) func(traceSample) float64 {
+	for _, ch := range []string{ChannelSpeed, ChannelThrottle, ChannelRPM} {
+		if opts.wants(ch) {
+			return func(s traceSample) float64 { return float64(sampleChannel(s, ch)) }
+		}
+	}
+	return func(s traceSample) float64 { return float64(s.data.NGear) }
+}
+
+func sampleChannel(s traceSample, channel string) int {
+	switch channel {
+	case ChannelSpeed:
+		return s.data.Speed
+	case ChannelRPM:
+		return s.data.RPM
+	case ChannelGear:
+		return s.data.NGear
+	case ChannelThrottle:
+		return s.data.Throttle
+	case ChannelBrake:
+		return s.data.Brake
+	case ChannelDRS:
+		return s.data.DRS
+	}
+	return 0
+}
+
+// downsampleLTTB Largest-Triangle-Three-Buckets：保留首尾點，每個桶取與前一點、下一桶平均點面積最大的樣本
+func downsampleLTTB(samples []traceSample, threshold int, series func(traceSample) float64) []traceSample {
+	n := len(samples)
+	if threshold >= n {
+		return samples
+	}
+
+	out := make([]traceSample, 0, threshold)
+	out = append(out, samples[0])
+
+	every := float64(n-2) / float64(threshold-2)
+	a := 0
+	for i := 0; i < threshold-2; i++ {
+		avgStart := int(float64(i+1)*every) + 1
+		avgEnd := min(int(float64(i+2)*every)+1, n)
+		var avgX, avgY float64
+		for j := avgStart; j < avgEnd; j++ {
+			avgX += samples[j].time
+			avgY += series(samples[j])
+		}
+		count := float64(avgEnd - avgStart)
+		avgX /= count
+		avgY /= count
+
+		rangeStart := int(float64(i)*every) + 1
+		rangeEnd := int(float64(i+1)*every) + 1
+		ax, ay := samples[a].time, series(samples[a])
+
+		maxArea := -1.0
+		next := rangeStart
+		for j := rangeStart; j < rangeEnd; j++ {
+			area := math.Abs((ax-avgX)*(series(samples[j])-ay) - (ax-samples[j].time)*(avgY-ay))
+			if area > maxArea {
+				maxArea = area
+				next = j
+			}
+		}
+		out = append(out, samples[next])
+		a = next
+	}
+
+	return append(out, samples[n-1])
+}
+
+func buildLapTelemetry(lap *LapData, samples []traceSample, start time.Time, opts TelemetryOptions) *LapTelemetry {
+	n := len(samples)
+	lt := &LapTelemetry{
+		LapNumber:  lap.LapNumber,
+		StartTime:  lap.StartTime,
+		EndTime:    lap.EndTime,
+		Samples:    n,
+		RawSamples: len(lap.TelemetryData),
+	}
+	if n > 0 {
+		lt.DriverNumber = samples[0].data.DriverNumber
+	}
+
+	if opts.TimeOffset {
+		lt.Time = make([]float64, n)
+	} else {
+		lt.Date = make([]string, n)
+	}
+	channels := make(map[string][]int)
+	for _, ch := range allTelemetryChannels {
+		if opts.wants(ch) {
+			channels[ch] = make([]int, n)
+		}
+	}
+
+	for i, s := range samples {
+		if opts.TimeOffset {
+			lt.Time[i] = s.time
+		} else if s.data.Date != "" {
+			lt.Date[i] = s.data.Date
+		} else {
+			lt.Date[i] = start.Add(time.Duration(s.time * float64(time.Second))).Format(time.RFC3339Nano)
+		}
+		for ch, values := range channels {
+			values[i] = sampleChannel(s, ch)
+		}
+	}
+
+	lt.Speed = channels[ChannelSpeed]
+	lt.RPM = channels[ChannelRPM]
+	lt.NGear = channels[ChannelGear]
+	lt.Throttle = channels[ChannelThrottle]
+	lt.Brake = channels[ChannelBrake]
+	lt.DRS = channels[ChannelDRS]
+	return lt
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+
+	"go.uber.org/zap"
+)
+
+// 遙測統計參數
+const (
+	telemetryChunkLaps = 10  // 每次向 OpenF1 撈取的圈數
+	fullThrottleLevel  = 98  // 油門不低於此值視為全油門
+	drsOpenLevel       = 10  // DRS 10/12/14 表示開啟
+	maxSampleGap       = 1.0 // 樣本間隔超過此秒數 (資料缺漏) 時只計此秒數
+	maxGear            = 8
+)
+
+// TelemetryStatsService 以分段撈取的 car_data 統計整個 session 的遙測通道
+type TelemetryStatsService struct {
+	*BaseService
+}
+
+func NewTelemetryStatsService(base *BaseService) *TelemetryStatsService {
+	return &TelemetryStatsService{BaseService: base}
+}
+
+// channelAcc 以時間加權累計遙測通道
+type channelAcc struct {
+	duration     float64
+	fullThrottle float64
+	braking      float64
+	drsOpen      float64
+	rpmTime      float64
+	gears        [maxGear + 1]float64
+	shifts       int
+}
+
+func (a *channelAcc) add(cd datasource.CarData, dt float64, prevGear int) {
+	a.duration += dt
+	if cd.Throttle >= fullThrottleLevel {
+		a.fullThrottle += dt
+	}
+	if cd.Brake > 0 {
+		a.braking += dt
+	}
+	if cd.DRS >= drsOpenLevel {
+		a.drsOpen += dt
+	}
+	a.rpmTime += float64(cd.RPM) * dt
+	if cd.NGear >= 0 && cd.NGear <= maxGear {
+		a.gears[cd.NGear] += dt
+	}
+	if prevGear > 0 && cd.NGear > 0 && cd.NGear != prevGear {
+		a.shifts++
+	}
+}
+
+func (a *channelAcc) pct(v float64) float64 {
+	if a.duration == 0 {
+		return 0
+	}
+	return v / a.duration * 100
+}
+
+// =======================
+// 主入口: Session 遙測統計
+// =======================
+
+// GetSessionTelemetryStats 每位車手的全油門比例、煞車比例、DRS 開啟時間、檔位分佈、平均轉速與每圈換檔次數。
+// 出站圈與沒有圈速的圈不列入；drivers 空白表示全部車手。
+func (s *TelemetryStatsService) GetSessionTelemetryStats(ctx context.Context, sessionKey int, drivers []int, filter LapFilter) (*SessionTelemetryStats, error) {
+	lapHistory, err := NewLapService(s.BaseService).GetFilteredLapHistory(ctx, sessionKey, filter)
+	if err != nil {
+		return nil, err
+	}
+	driverInfo, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	if len(drivers) == 0 {
+		for num := range lapHistory {
+			drivers = append(drivers, num)
+		}
+	}
+	sort.Ints(drivers)
+
+	stats := make([]*DriverTelemetryStats, len(drivers))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 3)
+	for i, num := range drivers {
+		laps := lapHistory[num]
+		if len(laps) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i, num int, laps []LapRecord) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			ds, err := s.driverTelemetryStats(ctx, sessionKey, num, laps)
+			if err != nil {
+				s.Logger.Warn("Failed to build telemetry stats", zap.Int("driver_number", num), zap.Error(err))
+				return
+			}
+			if d, ok := driverInfo[num]; ok {
+				ds.NameAcronym = d.NameAcronym
+				ds.TeamName = d.Team
+				ds.Color = d.Color
+			}
+			stats[i] = ds
+		}(i, num, laps)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SessionTelemetryStats{SessionKey: sessionKey}
+	var throttle, braking, shifts []float64
+	for _, ds := range stats {
+		if ds == nil || ds.Laps == 0 {
+			continue
+		}
+		result.Drivers = append(result.Drivers, *ds)
+		throttle = append(throttle, ds.FullThrottlePct)
+		braking = append(braking, ds.BrakingPct)
+		shifts = append(shifts, ds.GearShiftsPerLap)
+	}
+	result.FullThrottlePct = mean(throttle)
+	result.BrakingPct = mean(braking)
+	result.GearShiftsPerLap = mean(shifts)
+
+	return result, nil
+}
+
+// driverTelemetryStats 每 telemetryChunkLaps 圈撈一次 car_data，再依時間分配到各圈
+func (s *TelemetryStatsService) driverTelemetryStats(ctx context.Context, sessionKey, driverNum int, laps []LapRecord) (*DriverTelemetryStats, error) {
+	type window struct {
+		lap        LapRecord
+		start, end time.Time
+	}
+	var windows []window
+	for i, lap := range laps {
+		if lap.LapDuration <= 0 || lap.IsPitOutLap || lap.DateStart.IsZero() {
+			continue
+		}
+		end := lap.DateStart.Add(time.Duration(lap.LapDuration * float64(time.Second)))
+		if i+1 < len(laps) && !laps[i+1].DateStart.IsZero() {
+			end = laps[i+1].DateStart
+		}
+		windows = append(windows, window{lap: lap, start: lap.DateStart, end: end})
+	}
+
+	total := &channelAcc{}
+	ds := &DriverTelemetryStats{DriverNumber: driverNum}
+
+	for c := 0; c < len(windows); c += telemetryChunkLaps {
+		chunk := windows[c:min(c+telemetryChunkLaps, len(windows))]
+		from := chunk[0].start.Format(time.RFC3339Nano)
+		to := chunk[len(chunk)-1].end.Format(time.RFC3339Nano)
+
+		if err := s.throttle(ctx); err != nil {
+			return nil, err
+		}
+		samples, err := fetchRecords[datasource.CarData](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+			return s.DS.GetCarDataByLap(ctx, sessionKey, driverNum, url.QueryEscape(from), url.QueryEscape(to))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		type timedSample struct {
+			at   time.Time
+			data datasource.CarData
+		}
+		timed := make([]timedSample, 0, len(samples))
+		for _, cd := range samples {
+			if ts, err := parseSampleTime(cd.Date); err == nil {
+				timed = append(timed, timedSample{at: ts, data: cd})
+			}
+		}
+		sort.Slice(timed, func(i, j int) bool { return timed[i].at.Before(timed[j].at) })
+
+		k := 0
+		for _, w := range chunk {
+			lapAcc := &channelAcc{}
+			prevGear := 0
+			for ; k < len(timed) && timed[k].at.Before(w.end); k++ {
+				if timed[k].at.Before(w.start) {
+					continue
+				}
+				dt := maxSampleGap
+				if k+1 < len(timed) {
+					dt = min(timed[k+1].at.Sub(timed[k].at).Seconds(), maxSampleGap)
+				}
+				lapAcc.add(timed[k].data, dt, prevGear)
+				total.add(timed[k].data, dt, prevGear)
+				prevGear = timed[k].data.NGear
+			}
+			if lapAcc.duration == 0 {
+				continue
+			}
+
+			ds.Laps++
+			ds.LapStats = append(ds.LapStats, LapChannelStats{
+				LapNumber:       w.lap.LapNumber,
+				FullThrottlePct: lapAcc.pct(lapAcc.fullThrottle),
+				BrakingPct:      lapAcc.pct(lapAcc.braking),
+				DRSOpenTime:     lapAcc.drsOpen,
+				AverageRPM:      lapAcc.rpmTime / lapAcc.duration,
+				GearShifts:      lapAcc.shifts,
+			})
+		}
+	}
+
+	if total.duration == 0 {
+		return ds, nil
+	}
+	ds.FullThrottlePct = total.pct(total.fullThrottle)
+	ds.BrakingPct = total.pct(total.braking)
+	ds.DRSOpenTime = total.drsOpen
+	ds.AverageRPM = total.rpmTime / total.duration
+	ds.GearShifts = total.shifts
+	ds.GearShiftsPerLap = float64(total.shifts) / float64(ds.Laps)
+	ds.GearUsage = make([]float64, maxGear+1)
+	for gear, t := range total.gears {
+		ds.GearUsage[gear] = total.pct(t)
+	}
+	return ds, nil
+}
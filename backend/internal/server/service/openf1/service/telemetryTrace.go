@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"lovdlwlrma/backend/internal/server/service/openf1/datasource"
+)
+
+// =======================
+// 單圈遙測軌跡 (時間 / 距離)
+// =======================
+
+// traceSample 加上圈內時間與積分距離的遙測樣本
+type traceSample struct {
+	time     float64 // 距圈起點秒數
+	distance float64 // 公尺，由速度對時間積分
+	data     datasource.CarData
+}
+
+// lapTrace 單圈遙測，樣本依時間排序
+type lapTrace struct {
+	samples []traceSample
+}
+
+func (t *lapTrace) length() float64 {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	return t.samples[len(t.samples)-1].distance
+}
+
+func parseSampleTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// buildLapTrace 以梯形法對速度積分求距離；圈起點之前的樣本會被捨棄
+func buildLapTrace(lap *LapData) (*lapTrace, error) {
+	start, err := parseSampleTime(lap.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lap start %q: %w", lap.StartTime, err)
+	}
+
+	// 以解析後的時間排序，避免小數位數不同造成字串排序錯誤
+	var samples []traceSample
+	for _, cd := range lap.TelemetryData {
+		ts, err := parseSampleTime(cd.Date)
+		if err != nil {
+			continue
+		}
+		if t := ts.Sub(start).Seconds(); t >= 0 {
+			samples = append(samples, traceSample{time: t, data: cd})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].time < samples[j].time })
+
+	trace := &lapTrace{samples: samples}
+	for i := 1; i < len(samples); i++ {
+		prev, cur := &samples[i-1], &samples[i]
+		cur.distance = prev.distance + float64(prev.data.Speed+cur.data.Speed)/2/3.6*(cur.time-prev.time)
+	}
+
+	if len(trace.samples) < 2 {
+		return nil, fmt.Errorf("not enough telemetry samples for lap %d", lap.LapNumber)
+	}
+	return trace, nil
+}
+
+// atDistance 回傳距離 d 時的插值樣本：連續通道 (時間、速度、油門、轉速) 線性插值，
+// 離散通道 (煞車、檔位、DRS) 取前一筆
+func (t *lapTrace) atDistance(d float64) traceSample {
+	i := sort.Search(len(t.samples), func(i int) bool { return t.samples[i].distance >= d })
+	switch {
+	case i == 0:
+		return t.samples[0]
+	case i >= len(t.samples):
+		return t.samples[len(t.samples)-1]
+	}
+	return interpolateSample(t.samples[i-1], t.samples[i], d, func(s traceSample) float64 { return s.distance })
+}
+
+// atTime 與 atDistance 相同，但以圈內時間查詢
+func (t *lapTrace) atTime(sec float64) traceSample {
+	i := sort.Search(len(t.samples), func(i int) bool { return t.samples[i].time >= sec })
+	switch {
+	case i == 0:
+		return t.samples[0]
+	case i >= len(t.samples):
+		return t.samples[len(t.samples)-1]
+	}
+	return interpolateSample(t.samples[i-1], t.samples[i], sec, func(s traceSample) float64 { return s.time })
+}
+
+func interpolateSample(a, b traceSample, x float64, axis func(traceSample) float64) traceSample {
+	span := axis(b) - axis(a)
+	if span <= 0 {
+		return a
+	}
+	f := (x - axis(a)) / span
+	lerp := func(va, vb float64) float64 { return va + (vb-va)*f }
+
+	out := a
+	out.time = lerp(a.time, b.time)
+	out.distance = lerp(a.distance, b.distance)
+	out.data.Speed = int(lerp(float64(a.data.Speed), float64(b.data.Speed)) + 0.5)
+	out.data.Throttle = int(lerp(float64(a.data.Throttle), float64(b.data.Throttle)) + 0.5)
+	out.data.RPM = int(lerp(float64(a.data.RPM), float64(b.data.RPM)) + 0.5)
+	return out
+}
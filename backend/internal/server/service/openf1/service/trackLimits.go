@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// deletedTimeTolerance 訊息中的圈速 (毫秒精度) 與圈資料比對的容許誤差 (秒)
+const deletedTimeTolerance = 0.0015
+
+type TrackLimitsService struct {
+	*BaseService
+}
+
+func NewTrackLimitsService(base *BaseService) *TrackLimitsService {
+	return &TrackLimitsService{BaseService: base}
+}
+
+// =======================
+// 主入口: 超出賽道界線統計
+// =======================
+
+// GetSessionTrackLimits 以 race control 的刪圈訊息統計每位車手與每個彎的超出賽道界線次數；之後恢復的圈不計
+func (s *TrackLimitsService) GetSessionTrackLimits(ctx context.Context, sessionKey int) (*SessionTrackLimits, error) {
+	records, err := fetchRecords[RaceControlRecord](ctx, s.BaseService, func(ctx context.Context) ([]byte, error) {
+		return s.DS.GetRaceControlBySession(ctx, sessionKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := NewDriverRegistryService(s.BaseService).GetSessionDrivers(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch session drivers", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	// 圈資料用於把訊息對應到被刪除的圈，取得失敗時退回訊息中的圈數
+	lapHistory, err := NewLapService(s.BaseService).GetLapHistoryAll(ctx, sessionKey)
+	if err != nil {
+		s.Logger.Warn("Failed to fetch laps for track limits", zap.Int("session_key", sessionKey), zap.Error(err))
+	}
+
+	return summariseTrackLimits(sessionKey, ParseRaceControlEvents(records, EventTrackLimits, EventLapReinstated), lapHistory, drivers), nil
+}
+
+func summariseTrackLimits(sessionKey int, events []RaceControlEvent, lapHistory map[int][]LapRecord, drivers map[int]Driver) *SessionTrackLimits {
+	result := &SessionTrackLimits{SessionKey: sessionKey, Drivers: []DriverTrackLimits{}, Turns: []TurnTrackLimits{}}
+	byDriver := make(map[int]*DriverTrackLimits)
+	byTurn := make(map[int]*TurnTrackLimits)
+
+	// 與 markDeletedLaps 相同以 deletedLapIndex 對應到圈 (優先比對被刪除的圈速)，
+	// 恢復訊息沒有 "LAP n" 時 ev.Lap 只是當下領先者的圈數，不能直接拿來比對
+	resolveLap := func(ev RaceControlEvent) int {
+		laps := lapHistory[*ev.DriverNumber]
+		if i := deletedLapIndex(laps, ev); i >= 0 {
+			return laps[i].LapNumber
+		}
+		return ev.Lap
+	}
+	active := make(map[[2]int]int) // (車手, 圈) → 仍有效的刪圈訊息
+	for i, ev := range events {
+		if ev.DriverNumber == nil {
+			continue
+		}
+		key := [2]int{*ev.DriverNumber, resolveLap(ev)}
+		switch ev.Type {
+		case EventTrackLimits:
+			active[key] = i
+		case EventLapReinstated:
+			delete(active, key)
+		}
+	}
+	counted := make(map[int]int, len(active)) // 訊息 → 被刪除的圈
+	for key, i := range active {
+		counted[i] = key[1]
+	}
+
+	for i, ev := range events {
+		lap, ok := counted[i]
+		if !ok {
+			continue
+		}
+		num := *ev.DriverNumber
+		turn := 0
+		if ev.Turn != nil {
+			turn = *ev.Turn
+		}
+		result.Total++
+
+		dl, ok := byDriver[num]
+		if !ok {
+			dl = &DriverTrackLimits{DriverNumber: num}
+			if d, ok := drivers[num]; ok {
+				dl.NameAcronym = d.NameAcronym
+				dl.TeamName = d.Team
+				dl.Color = d.Color
+			}
+			byDriver[num] = dl
+		}
+		dl.Count++
+		dl.DeletedLaps = append(dl.DeletedLaps, lap)
+		if i := slices.IndexFunc(dl.Turns, func(t TrackLimitsTurnCount) bool { return t.Turn == turn }); i >= 0 {
+			dl.Turns[i].Count++
+		} else {
+			dl.Turns = append(dl.Turns, TrackLimitsTurnCount{Turn: turn, Count: 1})
+		}
+
+		tl, ok := byTurn[turn]
+		if !ok {
+			tl = &TurnTrackLimits{Turn: turn}
+			byTurn[turn] = tl
+		}
+		tl.Count++
+		if !slices.Contains(tl.Drivers, num) {
+			tl.Drivers = append(tl.Drivers, num)
+		}
+	}
+
+	for _, dl := range byDriver {
+		sort.Slice(dl.Turns, func(i, j int) bool { return dl.Turns[i].Turn < dl.Turns[j].Turn })
+		result.Drivers = append(result.Drivers, *dl)
+	}
+	sort.Slice(result.Drivers, func(i, j int) bool {
+		if result.Drivers[i].Count != result.Drivers[j].Count {
+			return result.Drivers[i].Count > result.Drivers[j].Count
+		}
+		return result.Drivers[i].DriverNumber < result.Drivers[j].DriverNumber
+	})
+
+	for _, tl := range byTurn {
+		sort.Ints(tl.Drivers)
+		result.Turns = append(result.Turns, *tl)
+	}
+	sort.Slice(result.Turns, func(i, j int) bool {
+		if result.Turns[i].Count != result.Turns[j].Count {
+			return result.Turns[i].Count > result.Turns[j].Count
+		}
+		return result.Turns[i].Turn < result.Turns[j].Turn
+	})
+
+	return result
+}
+
+// =======================
+// 刪除圈標記
+// =======================
+
+// markDeletedLaps 依時間順序套用刪圈與恢復圈速訊息。優先以訊息中的圈速比對車手的圈，
+// 找不到時退回訊息中的圈數
+func markDeletedLaps(lapHistory map[int][]LapRecord, events []RaceControlEvent) {
+	for _, ev := range events {
+		if ev.DriverNumber == nil {
+			continue
+		}
+		switch ev.Type {
+		case EventTrackLimits, EventLapDeleted, EventLapReinstated:
+		default:
+			continue
+		}
+
+		laps := lapHistory[*ev.DriverNumber]
+		i := deletedLapIndex(laps, ev)
+		if i < 0 {
+			continue
+		}
+		if ev.Type == EventLapReinstated {
+			laps[i].Deleted = false
+			laps[i].DeletedReason = ""
+			continue
+		}
+		laps[i].Deleted = true
+		laps[i].DeletedReason = deletedReason(ev.Reason)
+	}
+}
+
+func deletedLapIndex(laps []LapRecord, ev RaceControlEvent) int {
+	if t, ok := parseLapTime(ev.DeletedTime); ok {
+		if i := slices.IndexFunc(laps, func(l LapRecord) bool {
+			return math.Abs(l.LapDuration-t) <= deletedTimeTolerance
+		}); i >= 0 {
+			return i
+		}
+	}
+	return slices.IndexFunc(laps, func(l LapRecord) bool { return l.LapNumber == ev.Lap })
+}
+
+// deletedReason 去掉原因後面的圈數與時間，例如 "TRACK LIMITS AT TURN 4 LAP 12 14:05:01" → "TRACK LIMITS AT TURN 4"
+func deletedReason(reason string) string {
+	if loc := rcLapPattern.FindStringIndex(reason); loc != nil {
+		reason = reason[:loc[0]]
+	}
+	return strings.TrimSpace(reason)
+}
+
+// parseLapTime 解析 "1:23.456" 格式的圈速
+func parseLapTime(raw string) (float64, bool) {
+	minutes, seconds, ok := strings.Cut(raw, ":")
+	if !ok {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, false
+	}
+	sec, err := strconv.ParseFloat(seconds, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(m)*60 + sec, true
+}
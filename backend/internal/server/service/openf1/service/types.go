@@ -36,6 +36,25 @@ type Driver struct {
 	Team         string `json:"team_name"`
 	Color        string `json:"team_colour"`
 	HeadShotURL  string `json:"headshot_url"`
+	SessionKey   int    `json:"session_key"`
+	MeetingKey   int    `json:"meeting_key"`
+}
+
+// SeasonDriver 賽季車手與其車隊變動
+type SeasonDriver struct {
+	DriverNumber int                `json:"driver_number"`
+	FullName     string             `json:"full_name"`
+	NameAcronym  string             `json:"name_acronym"`
+	HeadShotURL  string             `json:"headshot_url"`
+	Teams        []SeasonDriverTeam `json:"teams"` // 依時間排序，轉隊時會有多筆
+}
+
+// SeasonDriverTeam 車手在某段期間所屬的車隊
+type SeasonDriverTeam struct {
+	TeamName        string `json:"team_name"`
+	Color           string `json:"team_colour"`
+	FirstSessionKey int    `json:"first_session_key"`
+	LastSessionKey  int    `json:"last_session_key"`
 }
 
 // ===== 比賽記錄資料結構 =====
@@ -56,6 +75,27 @@ type SessionResult struct {
 	FullName     string  `json:"full_name"`
 }
 
+// QualifyingResult 排位賽結果，duration 依序為 Q1/Q2/Q3 最佳單圈 (未參加該節為 null)
+type QualifyingResult struct {
+	Position     int        `json:"position"`
+	DriverNumber int        `json:"driver_number"`
+	Duration     []*float64 `json:"duration"`
+	DNF          bool       `json:"dnf"`
+	DNS          bool       `json:"dns"`
+	DSQ          bool       `json:"dsq"`
+	MeetingKey   int        `json:"meeting_key"`
+	SessionKey   int        `json:"session_key"`
+}
+
+// StartingGridRecord 表示正賽發車格 (含處罰後)
+type StartingGridRecord struct {
+	Position     int     `json:"position"`
+	DriverNumber int     `json:"driver_number"`
+	LapDuration  float64 `json:"lap_duration"`
+	MeetingKey   int     `json:"meeting_key"`
+	SessionKey   int     `json:"session_key"`
+}
+
 // PositionRecord 表示位置記錄
 type PositionRecord struct {
 	Date         time.Time `json:"date"`
@@ -73,7 +113,49 @@ type LapRecord struct {
 	SessionKey   int       `json:"session_key"`
 	MeetingKey   int       `json:"meeting_key"`
 	LapDuration  float64   `json:"lap_duration"`
+	IsPitOutLap  bool      `json:"is_pit_out_lap"`
 	IsDNF        bool      `json:"is_dnf"`
+
+	// 分段時間，0 表示 OpenF1 未提供
+	DurationSector1 float64 `json:"duration_sector_1"`
+	DurationSector2 float64 `json:"duration_sector_2"`
+	DurationSector3 float64 `json:"duration_sector_3"`
+
+	// 迷你分段狀態碼 (2048 黃、2049 綠、2051 紫、2064 進站...)
+	SegmentsSector1 []int `json:"segments_sector_1"`
+	SegmentsSector2 []int `json:"segments_sector_2"`
+	SegmentsSector3 []int `json:"segments_sector_3"`
+
+	// 測速點 (km/h)：i1、i2 為中間測速點，st 為 speed trap
+	I1Speed int `json:"i1_speed"`
+	I2Speed int `json:"i2_speed"`
+	STSpeed int `json:"st_speed"`
+
+	// 圈內有 SC / VSC / 紅旗時為該中立化類型，空字串表示綠旗圈
+	Neutralised string `json:"neutralised,omitempty"`
+
+	// race control 刪除的圈 (超出賽道界線等)，不列入最快圈計算
+	Deleted       bool   `json:"deleted"`
+	DeletedReason string `json:"deleted_reason,omitempty"`
+}
+
+// SectorDurations 依序回傳三個分段時間
+func (l LapRecord) SectorDurations() [3]float64 {
+	return [3]float64{l.DurationSector1, l.DurationSector2, l.DurationSector3}
+}
+
+// RaceControlRecord 表示賽事幹事 (race control) 訊息
+type RaceControlRecord struct {
+	Date         time.Time `json:"date"`
+	DriverNumber *int      `json:"driver_number"` // nil 表示全場
+	LapNumber    int       `json:"lap_number"`
+	Category     string    `json:"category"`
+	Flag         string    `json:"flag"`
+	Scope        string    `json:"scope"`
+	Sector       *int      `json:"sector"`
+	Message      string    `json:"message"`
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
 }
 
 // StintRecord 表示輪胎 stint 記錄
@@ -88,6 +170,16 @@ type StintRecord struct {
 	TyreAgeAtStart int    `json:"tyre_age_at_start"`
 }
 
+// PitRecord 表示進站記錄
+type PitRecord struct {
+	Date         time.Time `json:"date"`
+	DriverNumber int       `json:"driver_number"`
+	LapNumber    int       `json:"lap_number"`
+	PitDuration  float64   `json:"pit_duration"` // 維修區通過時間 (秒)
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+}
+
 // ===== 遙測資料結構 =====
 
 // LapData 表示單圈的遙測資料
@@ -98,24 +190,951 @@ type LapData struct {
 	TelemetryData []datasource.CarData
 }
 
+// LapTelemetry 經重新取樣 / 降採樣後的欄位式單圈遙測；未選取的通道省略
+type LapTelemetry struct {
+	LapNumber    int       `json:"lap_number"`
+	DriverNumber int       `json:"driver_number"`
+	StartTime    string    `json:"start_time"`
+	EndTime      string    `json:"end_time"`
+	Samples      int       `json:"samples"`
+	RawSamples   int       `json:"raw_samples"`
+	Date         []string  `json:"date,omitempty"`
+	Time         []float64 `json:"time,omitempty"` // 距圈起點秒數
+	Speed        []int     `json:"speed,omitempty"`
+	RPM          []int     `json:"rpm,omitempty"`
+	NGear        []int     `json:"n_gear,omitempty"`
+	Throttle     []int     `json:"throttle,omitempty"`
+	Brake        []int     `json:"brake,omitempty"`
+	DRS          []int     `json:"drs,omitempty"`
+}
+
 // ===== 積分榜相關資料結構 =====
 // StandingsHistory 包含車手和車隊的完整積分歷史
 type StandingsHistory struct {
 	Year            int                  `json:"year"`
 	TotalRounds     int                  `json:"total_rounds"`
-	Locations       []string             `json:"locations"`        // 所有站點名稱 (共用)
-	DriverStandings []DriverPointHistory `json:"driver_standings"` // 車手積分榜
+	Locations       []string             `json:"locations"`                // 所有站點名稱 (共用)
+	DriverStandings []DriverPointHistory `json:"driver_standings"`         // 車手積分榜
+	Rounds          []RoundStatus        `json:"rounds"`                   // 每站資料狀態
+	ScoredRounds    int                  `json:"scored_rounds"`            // 車手各站資料涵蓋的站數，第一個缺漏場次之後不提供積分與排名
+	Complete        bool                 `json:"complete"`                 // 已開賽的場次是否都有結果
+	MissingRounds   []int                `json:"missing_rounds,omitempty"` // 結果缺漏的場次
+	WhatIf          *WhatIfOptions       `json:"what_if,omitempty"`        // 套用的假設條件，官方積分榜為 nil
+}
+
+// RoundDataStatus 單站結果資料的狀態
+type RoundDataStatus string
+
+const (
+	RoundStatusComplete RoundDataStatus = "complete" // 已取得結果
+	RoundStatusMissing  RoundDataStatus = "missing"  // 抓取失敗，背景重試中或已放棄
+	RoundStatusPending  RoundDataStatus = "pending"  // 尚未開賽或官方尚未公布結果
+)
+
+// RoundStatus 單站資料狀態
+type RoundStatus struct {
+	Round       int             `json:"round"`
+	SessionKey  int             `json:"session_key"`
+	SessionName string          `json:"session_name"` // Race / Sprint
+	DateStart   string          `json:"date_start"`
+	Location    string          `json:"location"`
+	Status      RoundDataStatus `json:"status"`
+	Retrying    bool            `json:"retrying"`
 }
 
 // DriverPointHistory 車手積分歷史 (簡化版)
 type DriverPointHistory struct {
-	DriverNumber     int       `json:"driver_number"`
-	FullName         string    `json:"full_name"`
-	NameAcronym      string    `json:"name_acronym"`
-	TeamName         string    `json:"team_name"`
-	Color            string    `json:"team_colour"`
-	HeadShotURL      string    `json:"headshot_url"`
-	RoundPoints      []float64 `json:"round_points"`      // 每站獲得的積分
-	CumulativePoints []float64 `json:"cumulative_points"` // 每站後的累積積分
-	Positions        []int     `json:"positions"`         // 每站後的排名
+	DriverNumber          int       `json:"driver_number"`
+	FullName              string    `json:"full_name"`
+	NameAcronym           string    `json:"name_acronym"`
+	TeamName              string    `json:"team_name"`
+	Color                 string    `json:"team_colour"`
+	HeadShotURL           string    `json:"headshot_url"`
+	RoundPoints           []float64 `json:"round_points"`           // 每站獲得的積分
+	RoundTeams            []string  `json:"round_teams"`            // 每站代表的車隊 (未出賽為空字串)
+	CumulativePoints      []float64 `json:"cumulative_points"`      // 每站後的累積積分
+	RacePositions         []int     `json:"race_positions"`         // 每站完賽名次 (-1 = dnf/dns/dsq)
+	ChampionshipPositions []int     `json:"championship_positions"` // 每站後的積分榜排名 (同分依 countback)
+}
+
+// WhatIfOptions 假設性積分榜的條件
+type WhatIfOptions struct {
+	PointsSystem  *PointsSystem `json:"points_system,omitempty"`  // nil 表示沿用官方積分
+	ExcludeRounds []int         `json:"exclude_rounds,omitempty"` // 排除的場次 (對應 locations 的 1-based index)
+	Swaps         []ResultSwap  `json:"swaps,omitempty"`
+}
+
+// ResultSwap 交換兩位車手的完賽成績
+type ResultSwap struct {
+	Round   int `json:"round"` // 0 表示所有場次
+	DriverA int `json:"driver_a"`
+	DriverB int `json:"driver_b"`
+}
+
+// ===== 冠軍情境相關資料結構 =====
+
+// ChampionshipScenarios 冠軍情境計算結果
+type ChampionshipScenarios struct {
+	Year             int              `json:"year"`
+	DataComplete     bool             `json:"data_complete"` // 積分榜有缺漏場次時，情境計算可能不正確
+	CompletedRounds  int              `json:"completed_rounds"`
+	RemainingRaces   int              `json:"remaining_races"`
+	RemainingSprints int              `json:"remaining_sprints"`
+	RemainingEvents  []ScoringEvent   `json:"remaining_events"`
+	NextEvent        *ScoringEvent    `json:"next_event"`
+	Drivers          []TitleContender `json:"drivers"`
+	Teams            []TitleContender `json:"teams"`
+	DriverClinch     *ClinchScenario  `json:"driver_clinch"` // 賽季結束時為 nil
+	TeamClinch       *ClinchScenario  `json:"team_clinch"`
+}
+
+// ScoringEvent 剩餘賽程中可得分的場次 (Race 或 Sprint)
+type ScoringEvent struct {
+	Round    int        `json:"round"`
+	Name     string     `json:"name"`
+	IsSprint bool       `json:"is_sprint"`
+	Date     *time.Time `json:"date"`
+}
+
+// TitleContender 車手或車隊的爭冠狀態
+type TitleContender struct {
+	DriverNumber int     `json:"driver_number,omitempty"` // 車隊為 0
+	Name         string  `json:"name"`
+	TeamName     string  `json:"team_name"`
+	Points       float64 `json:"points"`
+	GapToLeader  float64 `json:"gap_to_leader"`
+	MaxPoints    float64 `json:"max_points"` // 剩餘場次全拿的理論最高積分
+	CanWinTitle  bool    `json:"can_win_title"`
+}
+
+// ClinchScenario 領先者在下一場封王的條件
+type ClinchScenario struct {
+	DriverNumber      int                 `json:"driver_number,omitempty"`
+	Name              string              `json:"name"`
+	Clinched          bool                `json:"clinched"`             // 已經封王
+	CanClinch         bool                `json:"can_clinch"`           // 下一場有機會封王
+	MinPointsToClinch float64             `json:"min_points_to_clinch"` // 不論對手成績都能封王的最低得分
+	RequiredMargins   []RivalMargin       `json:"required_margins"`
+	Combinations      []ClinchCombination `json:"combinations,omitempty"` // 僅車手榜提供
+}
+
+// RivalMargin 領先者在下一場必須比該對手多拿超過 Margin 分才能封王
+type RivalMargin struct {
+	DriverNumber int     `json:"driver_number,omitempty"`
+	Name         string  `json:"name"`
+	Margin       float64 `json:"margin"`
+}
+
+// ClinchCombination 領先者完賽名次對應的封王條件，名次 = 積分表長度+1 表示未進入積分區
+type ClinchCombination struct {
+	LeaderPosition  int              `json:"leader_position"`
+	LeaderPoints    float64          `json:"leader_points"`
+	RivalConditions []RivalCondition `json:"rival_conditions"` // 為空表示無條件封王
+}
+
+// RivalCondition 對手名次不得高於 FinishNoHigher；該對手拿到最快圈時改為 FinishNoHigherWithFastestLap。
+// 最快圈只會有一人拿到，因此同一組合中最多只有一位對手適用較嚴格的條件。
+type RivalCondition struct {
+	DriverNumber                 int    `json:"driver_number"`
+	Name                         string `json:"name"`
+	FinishNoHigher               int    `json:"finish_no_higher_than"`
+	FinishNoHigherWithFastestLap int    `json:"finish_no_higher_than_with_fastest_lap,omitempty"` // 與 FinishNoHigher 相同時省略
+}
+
+// ===== 隊友對決相關資料結構 =====
+
+// SeasonHeadToHead 賽季所有隊友組合的對決結果
+type SeasonHeadToHead struct {
+	Year  int                  `json:"year"`
+	Pairs []TeammateHeadToHead `json:"pairs"`
+}
+
+// TeammateHeadToHead 一組隊友的賽季對決
+type TeammateHeadToHead struct {
+	TeamName               string            `json:"team_name"`
+	Color                  string            `json:"team_colour"`
+	DriverA                HeadToHeadDriver  `json:"driver_a"` // 車號較小者
+	DriverB                HeadToHeadDriver  `json:"driver_b"`
+	Weekends               int               `json:"weekends"`                  // 兩人同隊出賽的站數
+	QualifyingMedianGap    float64           `json:"qualifying_median_gap"`     // A - B 秒數中位數，負值表示 A 較快
+	QualifyingMedianGapPct float64           `json:"qualifying_median_gap_pct"` // A - B 百分比中位數
+	Rounds                 []HeadToHeadRound `json:"rounds"`
+}
+
+// HeadToHeadDriver 單一車手在隊友對決中的統計
+type HeadToHeadDriver struct {
+	DriverNumber   int     `json:"driver_number"`
+	FullName       string  `json:"full_name"`
+	NameAcronym    string  `json:"name_acronym"`
+	QualifyingWins int     `json:"qualifying_wins"`
+	GridWins       int     `json:"grid_wins"`
+	RaceWins       int     `json:"race_wins"`
+	Points         float64 `json:"points"`       // 正賽 + Sprint
+	PointsShare    float64 `json:"points_share"` // 佔兩人總積分百分比
+	LapsLed        int     `json:"laps_led"`
+	DNFs           int     `json:"dnfs"`
+}
+
+// HeadToHeadRound 單站對決結果，winner 為 0 表示無法比較
+type HeadToHeadRound struct {
+	MeetingKey       int      `json:"meeting_key"`
+	Location         string   `json:"location"`
+	QualifyingWinner int      `json:"qualifying_winner"`
+	QualifyingGap    *float64 `json:"qualifying_gap"`     // A - B 秒數
+	QualifyingGapPct *float64 `json:"qualifying_gap_pct"` // A - B 百分比
+	GridWinner       int      `json:"grid_winner"`
+	RaceWinner       int      `json:"race_winner"`
+}
+
+// ===== 正賽配速相關資料結構 =====
+
+// SessionRacePace 單一 session 的燃油修正配速與輪胎衰退
+type SessionRacePace struct {
+	SessionKey      int              `json:"session_key"`
+	TotalLaps       int              `json:"total_laps"`
+	Options         RacePaceOptions  `json:"options"`
+	NeutralisedLaps []int            `json:"neutralised_laps"` // SC/VSC/紅旗圈
+	Drivers         []DriverRacePace `json:"drivers"`
+}
+
+// DriverRacePace 車手的配速分析，時間皆為燃油修正後秒數
+type DriverRacePace struct {
+	DriverNumber int            `json:"driver_number"`
+	NameAcronym  string         `json:"name_acronym"`
+	TeamName     string         `json:"team_name"`
+	Color        string         `json:"team_colour"`
+	CleanLaps    int            `json:"clean_laps"`
+	ExcludedLaps map[string]int `json:"excluded_laps"` // 排除原因 → 圈數
+	Compounds    []CompoundPace `json:"compounds"`
+	Stints       []StintPace    `json:"stints"`
+}
+
+// CompoundPace 同一配方所有 stint 的合併配速
+type CompoundPace struct {
+	Compound          string  `json:"compound"`
+	Laps              int     `json:"laps"`
+	MeanPace          float64 `json:"mean_pace"`
+	MedianPace        float64 `json:"median_pace"`
+	Degradation       float64 `json:"degradation"` // 秒/圈，依 stint 乾淨圈數加權
+	DegradationFitted bool    `json:"degradation_fitted"`
+}
+
+// StintPace 單一 stint 的配速與衰退斜率
+type StintPace struct {
+	StintNumber    int     `json:"stint_number"`
+	Compound       string  `json:"compound"`
+	LapStart       int     `json:"lap_start"`
+	LapEnd         int     `json:"lap_end"`
+	TyreAgeAtStart int     `json:"tyre_age_at_start"`
+	CleanLaps      int     `json:"clean_laps"`
+	MeanPace       float64 `json:"mean_pace"`
+	Degradation    float64 `json:"degradation"`      // 秒/圈 (對胎齡的斜率)
+	PaceAtNewTyre  float64 `json:"pace_at_new_tyre"` // 擬合線在胎齡 0 的圈速
+	Fitted         bool    `json:"fitted"`
+}
+
+// SessionLongRuns 練習賽單一 session 的長距離模擬
+type SessionLongRuns struct {
+	SessionKey int              `json:"session_key"`
+	Options    LongRunOptions   `json:"options"`
+	Drivers    []DriverLongRuns `json:"drivers"`
+}
+
+// MeetingLongRuns 大獎賽週末所有練習賽合併的長距離模擬
+type MeetingLongRuns struct {
+	MeetingKey int              `json:"meeting_key"`
+	Sessions   []string         `json:"sessions"`
+	Options    LongRunOptions   `json:"options"`
+	Drivers    []DriverLongRuns `json:"drivers"`
+}
+
+type DriverLongRuns struct {
+	DriverNumber int               `json:"driver_number"`
+	NameAcronym  string            `json:"name_acronym"`
+	TeamName     string            `json:"team_name"`
+	Color        string            `json:"team_colour"`
+	Compounds    []LongRunCompound `json:"compounds"`
+	Runs         []LongRun         `json:"runs"`
+}
+
+// LongRunCompound 同一配方所有長距離的合併結果，以圈數加權
+type LongRunCompound struct {
+	Compound    string  `json:"compound"`
+	Runs        int     `json:"runs"`
+	Laps        int     `json:"laps"`
+	AveragePace float64 `json:"average_pace"`
+	Degradation float64 `json:"degradation"` // 秒/圈
+}
+
+// LongRun 單一 stint 內連續的代表圈 (中間的塞車慢圈不計入)
+type LongRun struct {
+	SessionKey     int       `json:"session_key"`
+	StintNumber    int       `json:"stint_number"`
+	Compound       string    `json:"compound"`
+	LapStart       int       `json:"lap_start"`
+	LapEnd         int       `json:"lap_end"`
+	Laps           int       `json:"laps"`
+	TyreAgeAtStart int       `json:"tyre_age_at_start"`
+	AveragePace    float64   `json:"average_pace"`
+	MedianPace     float64   `json:"median_pace"`
+	CorrectedPace  float64   `json:"corrected_pace"` // 燃油修正到該段開始時的平均
+	Degradation    float64   `json:"degradation"`    // 秒/圈 (燃油修正後對胎齡的斜率)
+	LapTimes       []float64 `json:"lap_times"`
+}
+
+// SessionSectors 單一 session 的分段分析
+type SessionSectors struct {
+	SessionKey int              `json:"session_key"`
+	IdealLap   IdealLap         `json:"ideal_lap"`
+	Rankings   [3]SectorRanking `json:"rankings"`
+	Drivers    []DriverSectors  `json:"drivers"` // 依理論最快圈排序
+}
+
+// IdealLap 全場三個最佳分段的總和
+type IdealLap struct {
+	Duration float64          `json:"duration"` // 任一分段缺資料時為 0
+	Sectors  [3]IdealLapEntry `json:"sectors"`
+}
+
+type IdealLapEntry struct {
+	Sector       int     `json:"sector"`
+	Duration     float64 `json:"duration"`
+	DriverNumber int     `json:"driver_number"`
+	NameAcronym  string  `json:"name_acronym"`
+	LapNumber    int     `json:"lap_number"`
+}
+
+type DriverSectors struct {
+	DriverNumber      int           `json:"driver_number"`
+	NameAcronym       string        `json:"name_acronym"`
+	TeamName          string        `json:"team_name"`
+	Color             string        `json:"team_colour"`
+	BestSectors       [3]BestSector `json:"best_sectors"`
+	BestLap           float64       `json:"best_lap"`
+	BestLapNumber     int           `json:"best_lap_number"`
+	TheoreticalBest   float64       `json:"theoretical_best"`    // 三個最佳分段總和，缺資料時為 0
+	TheoreticalRank   int           `json:"theoretical_rank"`    // 0 表示無完整分段
+	LostToTheoretical float64       `json:"lost_to_theoretical"` // 最快圈 - 理論最快圈
+	GapToIdeal        float64       `json:"gap_to_ideal"`        // 理論最快圈 - 全場理想圈
+}
+
+type BestSector struct {
+	Sector    int     `json:"sector"`
+	Duration  float64 `json:"duration"`
+	LapNumber int     `json:"lap_number"`
+	Rank      int     `json:"rank"`
+	GapToBest float64 `json:"gap_to_best"`
+}
+
+// SectorRanking 單一分段的最佳時間排名
+type SectorRanking struct {
+	Sector  int                  `json:"sector"`
+	Entries []SectorRankingEntry `json:"entries"`
+}
+
+type SectorRankingEntry struct {
+	Position     int     `json:"position"`
+	DriverNumber int     `json:"driver_number"`
+	NameAcronym  string  `json:"name_acronym"`
+	Duration     float64 `json:"duration"`
+	GapToBest    float64 `json:"gap_to_best"`
+}
+
+// SessionSpeedTraps 單一 session 的測速資料
+type SessionSpeedTraps struct {
+	SessionKey int                `json:"session_key"`
+	Rankings   []SpeedTrapRanking `json:"rankings"`
+	Drivers    []DriverSpeedTraps `json:"drivers"`
+}
+
+type DriverSpeedTraps struct {
+	DriverNumber int          `json:"driver_number"`
+	NameAcronym  string       `json:"name_acronym"`
+	TeamName     string       `json:"team_name"`
+	Color        string       `json:"team_colour"`
+	Points       []SpeedPoint `json:"points"`
+}
+
+// SpeedPoint 單一測速點的速度 (km/h)；car_data 只有最高速
+type SpeedPoint struct {
+	Point   string  `json:"point"`
+	Top     int     `json:"top"`
+	TopLap  int     `json:"top_lap,omitempty"`
+	Median  float64 `json:"median,omitempty"`
+	Samples int     `json:"samples,omitempty"`
+}
+
+// SpeedTrapRanking 單一測速點依最高速的排名
+type SpeedTrapRanking struct {
+	Point   string           `json:"point"`
+	Entries []SpeedTrapEntry `json:"entries"`
+}
+
+type SpeedTrapEntry struct {
+	Position     int     `json:"position"`
+	DriverNumber int     `json:"driver_number"`
+	NameAcronym  string  `json:"name_acronym"`
+	TeamName     string  `json:"team_name"`
+	Top          int     `json:"top"`
+	Median       float64 `json:"median"`
+	GapToFastest int     `json:"gap_to_fastest"`
+}
+
+// SeasonSpeedDeficit 賽季各車隊 speed trap 極速與最快車隊的平均差距
+type SeasonSpeedDeficit struct {
+	Year        int                `json:"year"`
+	SessionName string             `json:"session_name"`
+	Sessions    int                `json:"sessions"`
+	Teams       []TeamSpeedDeficit `json:"teams"` // 依平均差距由小到大
+}
+
+type TeamSpeedDeficit struct {
+	TeamName        string             `json:"team_name"`
+	Color           string             `json:"team_colour"`
+	AverageTopSpeed float64            `json:"average_top_speed"`
+	AverageDeficit  float64            `json:"average_deficit"` // km/h
+	Rounds          []SessionTeamSpeed `json:"rounds"`
+}
+
+type SessionTeamSpeed struct {
+	SessionKey int    `json:"session_key"`
+	MeetingKey int    `json:"meeting_key"`
+	Location   string `json:"location"`
+	TopSpeed   int    `json:"top_speed"`
+	Deficit    int    `json:"deficit"`
+}
+
+// TelemetryComparison 兩圈遙測對齊到共同距離網格後的結果
+type TelemetryComparison struct {
+	Reference ComparedLap `json:"reference"`
+	Compare   ComparedLap `json:"compare"`
+	Step      float64     `json:"step"`     // 公尺
+	Distance  []float64   `json:"distance"` // 網格距離
+	Delta     []float64   `json:"delta"`    // 累積時間差 (compare - reference)，正值表示 compare 較慢
+}
+
+type ComparedLap struct {
+	TelemetryLapRef
+	Distance float64         `json:"lap_distance"` // 積分得到的單圈距離
+	Duration float64         `json:"lap_duration"` // 最後一筆樣本的圈內時間
+	Channels AlignedChannels `json:"channels"`
+}
+
+// AlignedChannels 對齊後的遙測通道，索引與 Distance 相同
+type AlignedChannels struct {
+	Time     []float64 `json:"time"`
+	Speed    []int     `json:"speed"`
+	Throttle []int     `json:"throttle"`
+	Brake    []int     `json:"brake"`
+	Gear     []int     `json:"n_gear"`
+	DRS      []int     `json:"drs"`
+}
+
+// CornerAnalysis 以參考圈編號的逐彎比較；距離皆已縮放到參考圈長度 (m)
+type CornerAnalysis struct {
+	SessionKey        int                `json:"session_key"`
+	CircuitKey        int                `json:"circuit_key"`
+	CircuitReference  bool               `json:"circuit_reference"` // 參考圈是否為賽道固定的參考圈 (否則為本 session 最快圈)
+	Reference         TelemetryLapRef    `json:"reference"`         // 決定彎道編號的參考圈，可能來自其他 session
+	ReferenceDistance float64            `json:"reference_distance"`
+	Laps              []TelemetryLapRef  `json:"laps"`
+	Corners           []CornerComparison `json:"corners"`
+}
+
+// CornerComparison 單一彎道各車手的數據，Drivers 順序與請求相同
+type CornerComparison struct {
+	Number       int            `json:"number"`
+	Name         string         `json:"name"` // 煞車區編號 (Zone1 …)，同一賽道各 session 一致，非官方彎道編號
+	ApexDistance float64        `json:"apex_distance"`
+	Drivers      []DriverCorner `json:"drivers"`
+}
+
+type DriverCorner struct {
+	DriverNumber    int      `json:"driver_number"`
+	LapNumber       int      `json:"lap_number"`
+	Detected        bool     `json:"detected"`
+	BrakePoint      float64  `json:"brake_point"`
+	BrakingDistance float64  `json:"braking_distance"`
+	EntrySpeed      int      `json:"entry_speed"`
+	ApexDistance    float64  `json:"apex_distance"`
+	ApexSpeed       int      `json:"apex_speed"`
+	ApexGear        int      `json:"apex_gear"`
+	ThrottlePickup  float64  `json:"throttle_pickup"`
+	BrakeDelta      *float64 `json:"brake_delta,omitempty"`      // 相對第一位車手，正值表示較晚煞車 (m)
+	ApexSpeedDelta  *int     `json:"apex_speed_delta,omitempty"` // 相對第一位車手 (km/h)
+}
+
+// DominanceMap 賽道優勢圖：每個迷你分段最快的車手或車隊
+type DominanceMap struct {
+	SessionKey   int                    `json:"session_key"`
+	MiniSectors  int                    `json:"mini_sectors"`
+	ByTeam       bool                   `json:"by_team"`
+	Reference    TelemetryLapRef        `json:"reference"` // 提供賽道形狀的圈
+	Distance     float64                `json:"distance"`
+	Participants []DominanceParticipant `json:"participants"`
+	Segments     []DominanceSegment     `json:"segments"`
+}
+
+// DominanceParticipant 車手 (key 為車號) 或車隊 (key 為車隊名稱)
+type DominanceParticipant struct {
+	Key         string  `json:"key"`
+	Label       string  `json:"label"`
+	Color       string  `json:"team_colour"`
+	SegmentsWon int     `json:"segments_won"`
+	Share       float64 `json:"share"` // 勝出分段百分比
+}
+
+type DominanceSegment struct {
+	Index         int           `json:"index"`
+	StartDistance float64       `json:"start_distance"`
+	EndDistance   float64       `json:"end_distance"`
+	Winner        string        `json:"winner"`
+	Color         string        `json:"team_colour"`
+	Margin        float64       `json:"margin"` // 領先第二名的秒數
+	Times         []SegmentTime `json:"times"`
+	Points        [][2]int      `json:"points"` // location x, y
+}
+
+type SegmentTime struct {
+	Key  string  `json:"key"`
+	Time float64 `json:"time"`
+}
+
+// SessionTelemetryStats 整個 session 的遙測通道統計；頂層欄位為各車手平均，可用於描述賽道特性
+type SessionTelemetryStats struct {
+	SessionKey       int                    `json:"session_key"`
+	FullThrottlePct  float64                `json:"full_throttle_pct"`
+	BrakingPct       float64                `json:"braking_pct"`
+	GearShiftsPerLap float64                `json:"gear_shifts_per_lap"`
+	Drivers          []DriverTelemetryStats `json:"drivers"`
+}
+
+type DriverTelemetryStats struct {
+	DriverNumber     int               `json:"driver_number"`
+	NameAcronym      string            `json:"name_acronym"`
+	TeamName         string            `json:"team_name"`
+	Color            string            `json:"team_colour"`
+	Laps             int               `json:"laps"`
+	FullThrottlePct  float64           `json:"full_throttle_pct"`
+	BrakingPct       float64           `json:"braking_pct"`
+	DRSOpenTime      float64           `json:"drs_open_time"` // 秒
+	AverageRPM       float64           `json:"average_rpm"`
+	GearShifts       int               `json:"gear_shifts"`
+	GearShiftsPerLap float64           `json:"gear_shifts_per_lap"`
+	GearUsage        []float64         `json:"gear_usage"` // 索引為檔位 (0 為空檔)，值為時間百分比
+	LapStats         []LapChannelStats `json:"lap_stats"`
+}
+
+type LapChannelStats struct {
+	LapNumber       int     `json:"lap_number"`
+	FullThrottlePct float64 `json:"full_throttle_pct"`
+	BrakingPct      float64 `json:"braking_pct"`
+	DRSOpenTime     float64 `json:"drs_open_time"`
+	AverageRPM      float64 `json:"average_rpm"`
+	GearShifts      int     `json:"gear_shifts"`
+}
+
+// RaceControlEvent 解析後的 race control 訊息；欄位名稱與前端 RaceControlData 相同
+type RaceControlEvent struct {
+	Type         string       `json:"type"`
+	UTC          time.Time    `json:"utc"`
+	Lap          int          `json:"lap"`
+	Category     string       `json:"category"`
+	Flag         string       `json:"flag,omitempty"`
+	Scope        string       `json:"scope,omitempty"`
+	Sector       *int         `json:"sector,omitempty"`
+	Message      string       `json:"message"`
+	DriverNumber *int         `json:"driver_number,omitempty"` // 主要車手，全場訊息為 nil
+	Drivers      []int        `json:"drivers,omitempty"`       // 訊息中提到的所有車手
+	Turn         *int         `json:"turn,omitempty"`
+	DeletedTime  string       `json:"deleted_time,omitempty"` // 被刪除的圈速
+	Reason       string       `json:"reason,omitempty"`
+	Penalty      *RacePenalty `json:"penalty,omitempty"`
+	SessionKey   int          `json:"session_key"`
+	MeetingKey   int          `json:"meeting_key"`
+}
+
+type RacePenalty struct {
+	Type       string `json:"type"`
+	Seconds    int    `json:"seconds,omitempty"`
+	GridPlaces int    `json:"grid_places,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// ===== 中立化 (SC / VSC / 紅旗) =====
+
+type SessionNeutralisations struct {
+	SessionKey      int                    `json:"session_key"`
+	Periods         []NeutralisationPeriod `json:"periods"`
+	NeutralisedLaps []int                  `json:"neutralised_laps"` // 依領先者圈數
+}
+
+type NeutralisationPeriod struct {
+	Type     string               `json:"type"` // SC / VSC / RED
+	Start    time.Time            `json:"start"`
+	End      *time.Time           `json:"end"` // nil 表示直到 session 結束
+	StartLap int                  `json:"start_lap"`
+	EndLap   int                  `json:"end_lap"`
+	PitStops []NeutralisedPitStop `json:"pit_stops,omitempty"`
+}
+
+// NeutralisedPitStop 中立化期間進站的車手與名次得失 (正值為進步)
+type NeutralisedPitStop struct {
+	DriverNumber   int     `json:"driver_number"`
+	NameAcronym    string  `json:"name_acronym"`
+	TeamName       string  `json:"team_name"`
+	Color          string  `json:"team_colour"`
+	LapNumber      int     `json:"lap_number"`
+	PitDuration    float64 `json:"pit_duration"`
+	PositionBefore int     `json:"position_before"`
+	PositionAfter  int     `json:"position_after"`
+	Gained         int     `json:"gained"`
+}
+
+// ===== 幹事調查與處罰 =====
+
+// Incident 單一事件從注意、調查到裁決的過程
+type Incident struct {
+	ID              string       `json:"id"`
+	SessionKey      int          `json:"session_key"`
+	MeetingKey      int          `json:"meeting_key"`
+	SessionName     string       `json:"session_name,omitempty"`
+	Location        string       `json:"location,omitempty"`
+	Lap             int          `json:"lap"`
+	Turn            *int         `json:"turn,omitempty"`
+	Drivers         []int        `json:"drivers"`
+	Description     string       `json:"description,omitempty"`
+	Reason          string       `json:"reason,omitempty"`
+	Status          string       `json:"status"`             // noted / under_investigation / decided
+	Decision        string       `json:"decision,omitempty"` // no_further_action 或處罰類型
+	Penalty         *RacePenalty `json:"penalty,omitempty"`
+	PenalisedDriver *int         `json:"penalised_driver,omitempty"`
+	PenaltyPoints   int          `json:"penalty_points"`
+	NotedAt         *time.Time   `json:"noted_at,omitempty"`
+	InvestigatedAt  *time.Time   `json:"investigated_at,omitempty"`
+	DecidedAt       *time.Time   `json:"decided_at,omitempty"`
+}
+
+type SessionIncidents struct {
+	SessionKey int        `json:"session_key"`
+	Incidents  []Incident `json:"incidents"`
+}
+
+type SeasonPenalties struct {
+	Year      int                   `json:"year"`
+	Sessions  int                   `json:"sessions"`
+	Options   PenaltyTrackerOptions `json:"options"`
+	Drivers   []DriverPenaltyRecord `json:"drivers"`
+	Incidents []Incident            `json:"incidents"`
+}
+
+type DriverPenaltyRecord struct {
+	DriverNumber        int    `json:"driver_number"`
+	NameAcronym         string `json:"name_acronym"`
+	Incidents           int    `json:"incidents"`      // 被列入的事件數
+	Investigations      int    `json:"investigations"` // 其中進入調查的數量
+	Penalties           int    `json:"penalties"`      // 不含申誡
+	TimePenaltySeconds  int    `json:"time_penalty_seconds"`
+	GridPlaces          int    `json:"grid_places"`
+	Reprimands          int    `json:"reprimands"`
+	PenaltyPoints       int    `json:"penalty_points"`
+	PointsToBan         int    `json:"points_to_ban"`
+	ReprimandsToPenalty int    `json:"reprimands_to_penalty"`
+	AtRisk              bool   `json:"at_risk"`
+}
+
+// ===== 超出賽道界線 =====
+
+type SessionTrackLimits struct {
+	SessionKey int                 `json:"session_key"`
+	Total      int                 `json:"total"`
+	Drivers    []DriverTrackLimits `json:"drivers"`
+	Turns      []TurnTrackLimits   `json:"turns"`
+}
+
+type DriverTrackLimits struct {
+	DriverNumber int                    `json:"driver_number"`
+	NameAcronym  string                 `json:"name_acronym"`
+	TeamName     string                 `json:"team_name"`
+	Color        string                 `json:"team_colour"`
+	Count        int                    `json:"count"`
+	DeletedLaps  []int                  `json:"deleted_laps"`
+	Turns        []TrackLimitsTurnCount `json:"turns"`
+}
+
+type TrackLimitsTurnCount struct {
+	Turn  int `json:"turn"` // 0 表示訊息未註明彎道
+	Count int `json:"count"`
+}
+
+type TurnTrackLimits struct {
+	Turn    int   `json:"turn"`
+	Count   int   `json:"count"`
+	Drivers []int `json:"drivers"`
+}
+
+// ===== 圈位置圖 =====
+
+type LapChart struct {
+	SessionKey int           `json:"session_key"`
+	TotalLaps  int           `json:"total_laps"`
+	Drivers    []int         `json:"drivers"`
+	Grid       []GridSlot    `json:"grid"`
+	Laps       []LapChartLap `json:"laps"`
+}
+
+type GridSlot struct {
+	Position     int     `json:"position"`
+	DriverNumber int     `json:"driver_number"`
+	LapDuration  float64 `json:"lap_duration,omitempty"` // 排位成績
+}
+
+// LapChartLap 單圈結束時的名次，Cells 依名次排序
+type LapChartLap struct {
+	Lap   int            `json:"lap"`
+	Cells []LapChartCell `json:"cells"`
+}
+
+type LapChartCell struct {
+	DriverNumber int    `json:"driver_number"`
+	Position     int    `json:"position"`
+	Status       string `json:"status"`              // running / pitted / lapped / finished / retired
+	LapsDown     int    `json:"laps_down,omitempty"` // 落後領先者的圈數
+}
+
+// ===== 正賽名次得失 =====
+
+type RaceSummary struct {
+	SessionKey int                  `json:"session_key"`
+	Drivers    []RacePositionChange `json:"drivers"` // 依最終名次排序，未完賽者在後
+	Gainers    []RacePositionChange `json:"gainers"` // 淨得名次最多的完賽車手
+	Losers     []RacePositionChange `json:"losers"`
+}
+
+// RacePositionChange 名次為 0 表示沒有資料；得失為正數表示進步，無法計算時為 null
+type RacePositionChange struct {
+	DriverNumber   int    `json:"driver_number"`
+	NameAcronym    string `json:"name_acronym"`
+	TeamName       string `json:"team_name"`
+	Color          string `json:"team_colour"`
+	Status         string `json:"status"` // finished / dnf / dns / dsq
+	GridPosition   int    `json:"grid_position"`
+	PitLaneStart   bool   `json:"pit_lane_start"`
+	StartPosition  int    `json:"start_position"` // 第一圈第一段結束
+	Lap1Position   int    `json:"lap1_position"`
+	FinishPosition int    `json:"finish_position"`
+	StartGained    *int   `json:"start_gained"`
+	Lap1Gained     *int   `json:"lap1_gained"` // 發車格到第一圈結束 (含起跑)
+	NetGained      *int   `json:"net_gained"`  // 發車格到最終名次，只計完賽者
+}
+
+type SeasonRaceSummary struct {
+	Year         int                  `json:"year"`
+	Races        int                  `json:"races"`
+	Drivers      []DriverRaceDayStats `json:"drivers"`       // 依平均淨得名次排序
+	BestStarters []DriverRaceDayStats `json:"best_starters"` // 第一圈平均得名次最多
+	BestClimbers []DriverRaceDayStats `json:"best_climbers"` // 正賽平均淨得名次最多
+}
+
+type DriverRaceDayStats struct {
+	DriverNumber   int     `json:"driver_number"`
+	NameAcronym    string  `json:"name_acronym"`
+	TeamName       string  `json:"team_name"`
+	Color          string  `json:"team_colour"`
+	Races          int     `json:"races"`
+	RacesGained    int     `json:"races_gained"` // 淨得名次為正的場次
+	StartGained    int     `json:"start_gained"`
+	Lap1Gained     int     `json:"lap1_gained"`
+	NetGained      int     `json:"net_gained"`
+	AvgStartGained float64 `json:"avg_start_gained"`
+	AvgLap1Gained  float64 `json:"avg_lap1_gained"`
+	AvgNetGained   float64 `json:"avg_net_gained"`
+}
+
+// ===== 排位賽淘汰分析 =====
+
+type QualifyingAnalysis struct {
+	SessionKey int                 `json:"session_key"`
+	Segments   []QualifyingSegment `json:"segments"`
+	Drivers    []QualifyingDriver  `json:"drivers"` // 依最終排位排序
+}
+
+type QualifyingSegment struct {
+	Name          string     `json:"name"` // Q1 / Q2 / Q3
+	Start         time.Time  `json:"start"`
+	End           *time.Time `json:"end"` // 方格旗，尚未結束為 null
+	Participants  int        `json:"participants"`
+	Advance       int        `json:"advance"` // 晉級人數，最後一節為 0
+	FastestLap    *float64   `json:"fastest_lap"`
+	FastestDriver *int       `json:"fastest_driver"`
+	CutoffTime    *float64   `json:"cutoff_time"`   // 最後一位晉級者的最佳圈
+	CutoffDriver  *int       `json:"cutoff_driver"` // 最後一位晉級者
+	Threshold107  *float64   `json:"threshold_107,omitempty"`
+	Eliminated    []int      `json:"eliminated,omitempty"`
+}
+
+type QualifyingDriver struct {
+	DriverNumber int                       `json:"driver_number"`
+	NameAcronym  string                    `json:"name_acronym"`
+	TeamName     string                    `json:"team_name"`
+	Color        string                    `json:"team_colour"`
+	Position     int                       `json:"position"`
+	EliminatedIn string                    `json:"eliminated_in,omitempty"`
+	KnockedOutBy *int                      `json:"knocked_out_by"` // 最後把該車手擠出晉級區的車手
+	Outside107   bool                      `json:"outside_107"`
+	Segments     []DriverQualifyingSegment `json:"segments"`
+}
+
+type DriverQualifyingSegment struct {
+	Segment      string          `json:"segment"`
+	Position     int             `json:"position"` // 該節名次
+	BestLap      *float64        `json:"best_lap"`
+	GapToFastest *float64        `json:"gap_to_fastest"`
+	GapToCutoff  *float64        `json:"gap_to_cutoff"` // 正數為慢於晉級門檻
+	Runs         []QualifyingRun `json:"runs"`
+}
+
+// QualifyingRun 單趟出站 (出站圈到回站)
+type QualifyingRun struct {
+	Run         int      `json:"run"`
+	TimedLaps   int      `json:"timed_laps"`
+	BestLap     *float64 `json:"best_lap"`
+	Improvement *float64 `json:"improvement"` // 與之前最佳圈的差，負數為變快
+}
+
+// ===== 纏鬥與 DRS 車列 =====
+
+type SessionBattles struct {
+	SessionKey int           `json:"session_key"`
+	Options    BattleOptions `json:"options"`
+	Battles    []Battle      `json:"battles"`
+	Trains     []DRSTrain    `json:"trains"`
+}
+
+// Battle 兩車在門檻內連續纏鬥的圈段；差距為通過計時線的時間差
+type Battle struct {
+	Defender    int     `json:"defender"` // 纏鬥開始時在前的車手
+	Attacker    int     `json:"attacker"`
+	StartLap    int     `json:"start_lap"`
+	EndLap      int     `json:"end_lap"`
+	Laps        int     `json:"laps"` // 不含中立化圈
+	MinGap      float64 `json:"min_gap"`
+	AvgGap      float64 `json:"avg_gap"`
+	Outcome     string  `json:"outcome"`                // overtake / pit_divergence / gap_opened / retirement / race_end
+	OvertakeLap int     `json:"overtake_lap,omitempty"` // 最後一次順序互換的圈
+}
+
+// DRSTrain 3 車以上各自在前車門檻內的車列，以車頭識別
+type DRSTrain struct {
+	Leader   int    `json:"leader"`
+	Drivers  []int  `json:"drivers"` // 曾在車列中的車手，依加入順序
+	StartLap int    `json:"start_lap"`
+	EndLap   int    `json:"end_lap"`
+	Laps     int    `json:"laps"`
+	MaxCars  int    `json:"max_cars"`
+	Outcome  string `json:"outcome"`
+}
+
+// ===== 進站攻防 (undercut / overcut) =====
+
+type SessionPitStrategy struct {
+	SessionKey int                `json:"session_key"`
+	Options    PitStrategyOptions `json:"options"`
+	Attempts   []PitAttempt       `json:"attempts"`
+	Teams      []TeamPitStrategy  `json:"teams"`
+}
+
+type SeasonPitStrategy struct {
+	Year     int                `json:"year"`
+	Races    int                `json:"races"`
+	Options  PitStrategyOptions `json:"options"`
+	Teams    []TeamPitStrategy  `json:"teams"`
+	Attempts []PitAttempt       `json:"attempts"`
+}
+
+// PitAttempt 攻方為進站前在後的車手；差距為攻方減守方通過計時線的時間，正數為落後
+type PitAttempt struct {
+	SessionKey          int     `json:"session_key"`
+	Type                string  `json:"type"` // undercut / overcut
+	Attacker            int     `json:"attacker"`
+	Defender            int     `json:"defender"`
+	AttackerTeam        string  `json:"attacker_team"`
+	DefenderTeam        string  `json:"defender_team"`
+	FirstStopLap        int     `json:"first_stop_lap"`
+	AttackerPitLap      int     `json:"attacker_pit_lap"`
+	DefenderPitLap      int     `json:"defender_pit_lap"`
+	AttackerPitDuration float64 `json:"attacker_pit_duration"`
+	DefenderPitDuration float64 `json:"defender_pit_duration"`
+	AttackerCompound    string  `json:"attacker_compound,omitempty"` // 進站後換上的輪胎
+	DefenderCompound    string  `json:"defender_compound,omitempty"`
+	GapBefore           float64 `json:"gap_before"`
+	GapAfter            float64 `json:"gap_after"`
+	TimeGained          float64 `json:"time_gained"`  // 正數為攻方得利
+	OutLapGain          float64 `json:"out_lap_gain"` // 第一位進站車手的出站圈 (對手隔圈進站時即對上對手的進站圈)
+	InLapGain           float64 `json:"in_lap_gain"`  // 第二位進站車手的進站圈，與出站圈同圈時為 0
+	OtherGain           float64 `json:"other_gain"`   // 其餘圈 (含第一位車手的進站圈)
+	PositionBefore      int     `json:"position_before"`
+	PositionAfter       int     `json:"position_after"`
+	PositionsGained     int     `json:"positions_gained"`
+	Success             bool    `json:"success"`   // 攻方出站後在守方之前
+	Teammates           bool    `json:"teammates"` // 同隊車手之間的進站順序，不列入車隊彙整
+}
+
+type TeamPitStrategy struct {
+	TeamName           string  `json:"team_name"`
+	Undercuts          int     `json:"undercuts"`
+	UndercutSuccesses  int     `json:"undercut_successes"`
+	Overcuts           int     `json:"overcuts"`
+	OvercutSuccesses   int     `json:"overcut_successes"`
+	SuccessRate        float64 `json:"success_rate"`
+	TimeGained         float64 `json:"time_gained"` // 作為攻方的累計時間得失
+	AvgTimeGained      float64 `json:"avg_time_gained"`
+	Defences           int     `json:"defences"` // 作為守方被攻擊的次數
+	SuccessfulDefences int     `json:"successful_defences"`
+}
+
+// ===== 進站策略模擬 =====
+
+// CompoundModel 配方模型：新胎圈速相對基準圈速的偏移與每圈衰退 (秒)
+type CompoundModel struct {
+	Compound    string  `json:"compound"`
+	Offset      float64 `json:"offset"`
+	Degradation float64 `json:"degradation"`
+	Source      string  `json:"source"` // input / archive / default
+}
+
+// StrategySimInputs 實際使用的模擬輸入
+type StrategySimInputs struct {
+	TotalLaps            int               `json:"total_laps"`
+	BasePace             float64           `json:"base_pace"`
+	PitLoss              float64           `json:"pit_loss"`
+	FuelCorrection       float64           `json:"fuel_correction"`
+	Compounds            []CompoundModel   `json:"compounds"`
+	RequireTwoCompounds  bool              `json:"require_two_compounds"`
+	MinStintLaps         int               `json:"min_stint_laps"`
+	MaxStops             int               `json:"max_stops"`
+	SafetyCarProbability float64           `json:"safety_car_probability"`
+	SafetyCarLaps        int               `json:"safety_car_laps"`
+	SafetyCarRaces       int               `json:"safety_car_races"` // 推算 SC 機率使用的正賽場數
+	Simulations          int               `json:"simulations"`
+	Sources              map[string]string `json:"sources"` // 輸入項目 → 資料來源
+}
+
+type StrategySimulation struct {
+	SessionKey           int               `json:"session_key"`
+	Inputs               StrategySimInputs `json:"inputs"`
+	Evaluated            int               `json:"evaluated"` // 評估過的策略數
+	Optimal              *StrategyPlan     `json:"optimal"`
+	OptimalWithSafetyCar *StrategyPlan     `json:"optimal_with_safety_car"` // 蒙地卡羅期望時間最短
+	Plans                []StrategyPlan    `json:"plans"`                   // 各配方順序的最佳進站圈
+}
+
+type StrategyPlan struct {
+	Stops          int      `json:"stops"`
+	Compounds      []string `json:"compounds"`
+	PitLaps        []int    `json:"pit_laps"`
+	TotalTime      float64  `json:"total_time"`
+	GapToOptimal   float64  `json:"gap_to_optimal"`
+	ExpectedTime   float64  `json:"expected_time,omitempty"`   // 含 SC 的期望完賽時間
+	WinProbability float64  `json:"win_probability,omitempty"` // 在模擬中為最快策略的比例
 }
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidWhatIf 假設條件不合法 (場次超出範圍、交換同一位車手等)
+var ErrInvalidWhatIf = errors.New("invalid what-if options")
+
+// =======================
+// What-if 積分榜
+// =======================
+
+// applyWhatIf 依假設條件產生新的場次與結果，不會修改傳入的 resultsMap。
+// 有指定積分制度或交換成績時，積分依完賽名次重新計算；只排除場次時沿用官方積分。
+func applyWhatIf(year int, sessions []Session, resultsMap map[int][]SessionResult, opts *WhatIfOptions) ([]Session, map[int][]SessionResult, error) {
+	if err := validateWhatIf(len(sessions), opts); err != nil {
+		return nil, nil, err
+	}
+
+	official := OfficialPointsSystem(year)
+	system := opts.PointsSystem
+	recompute := system != nil || len(opts.Swaps) > 0
+	if system == nil {
+		system = official
+	}
+
+	excluded := make(map[int]bool, len(opts.ExcludeRounds))
+	for _, round := range opts.ExcludeRounds {
+		excluded[round] = true
+	}
+
+	var keptSessions []Session
+	newResults := make(map[int][]SessionResult, len(resultsMap))
+	for i, sess := range sessions {
+		round := i + 1
+		if excluded[round] {
+			continue
+		}
+		keptSessions = append(keptSessions, sess)
+
+		original, ok := resultsMap[sess.SessionKey]
+		if !ok {
+			continue
+		}
+		results := append([]SessionResult(nil), original...)
+		isSprint := sess.SessionName == "Sprint"
+
+		// 最快圈必須在交換成績前由官方積分推得
+		fastest := inferFastestLap(official, results, isSprint)
+		for _, sw := range opts.Swaps {
+			if sw.Round == 0 || sw.Round == round {
+				fastest = swapResults(results, sw, fastest)
+			}
+		}
+
+		if recompute {
+			for j := range results {
+				results[j].Points = system.PointsFor(scoringPosition(results[j]), isSprint, results[j].DriverNumber == fastest)
+			}
+		}
+		newResults[sess.SessionKey] = results
+	}
+
+	return keptSessions, newResults, nil
+}
+
+func validateWhatIf(totalRounds int, opts *WhatIfOptions) error {
+	for _, round := range opts.ExcludeRounds {
+		if round < 1 || round > totalRounds {
+			return fmt.Errorf("%w: exclude round %d out of range 1-%d", ErrInvalidWhatIf, round, totalRounds)
+		}
+	}
+	for _, sw := range opts.Swaps {
+		if sw.Round < 0 || sw.Round > totalRounds {
+			return fmt.Errorf("%w: swap round %d out of range 1-%d", ErrInvalidWhatIf, sw.Round, totalRounds)
+		}
+		if sw.DriverA == sw.DriverB {
+			return fmt.Errorf("%w: cannot swap driver %d with itself", ErrInvalidWhatIf, sw.DriverA)
+		}
+	}
+	return nil
+}
+
+// scoringPosition 可得分的名次；DSQ/DNS 或無名次者回傳 0。
+// 完成足夠圈數的 DNF 仍有名次，依規則可以得分。
+func scoringPosition(r SessionResult) int {
+	if r.DSQ || r.DNS || r.Position <= 0 {
+		return 0
+	}
+	return r.Position
+}
+
+// inferFastestLap 從官方積分推回最快圈加分的車手，找不到時回傳 0
+func inferFastestLap(official *PointsSystem, results []SessionResult, isSprint bool) int {
+	if isSprint || official.FastestLapPoint == 0 {
+		return 0
+	}
+	for _, r := range results {
+		base := official.PointsFor(scoringPosition(r), false, false)
+		if r.Points-base >= official.FastestLapPoint {
+			return r.DriverNumber
+		}
+	}
+	return 0
+}
+
+// swapResults 交換兩位車手的完賽成績 (名次、狀態、圈數、時間)，回傳交換後的最快圈車手。
+// 任一車手不在該場結果中時不做任何事。
+func swapResults(results []SessionResult, sw ResultSwap, fastest int) int {
+	ia, ib := -1, -1
+	for i, r := range results {
+		switch r.DriverNumber {
+		case sw.DriverA:
+			ia = i
+		case sw.DriverB:
+			ib = i
+		}
+	}
+	if ia < 0 || ib < 0 {
+		return fastest
+	}
+
+	a, b := &results[ia], &results[ib]
+	a.Position, b.Position = b.Position, a.Position
+	a.DNF, b.DNF = b.DNF, a.DNF
+	a.DNS, b.DNS = b.DNS, a.DNS
+	a.DSQ, b.DSQ = b.DSQ, a.DSQ
+	a.NumberOfLaps, b.NumberOfLaps = b.NumberOfLaps, a.NumberOfLaps
+	a.Duration, b.Duration = b.Duration, a.Duration
+	a.Points, b.Points = b.Points, a.Points
+
+	switch fastest {
+	case sw.DriverA:
+		return sw.DriverB
+	case sw.DriverB:
+		return sw.DriverA
+	}
+	return fastest
+}
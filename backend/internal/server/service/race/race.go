@@ -83,3 +83,16 @@ func (s *Service) GetAllRaces() (map[int]*Race, error) {
 	}
 	return raceMap, nil
 }
+
+// GetRacesByYear 拿到指定年份的完整賽歷，依 round 排序
+func (s *Service) GetRacesByYear(year int) ([]*Race, error) {
+	races, err := s.fetchRaces(year)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(races, func(i, j int) bool {
+		return races[i].Round < races[j].Round
+	})
+	return races, nil
+}
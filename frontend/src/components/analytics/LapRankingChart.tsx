@@ -2,7 +2,7 @@ import { useMemo } from "react";
 import { Line } from "react-chartjs-2";
 import { Driver } from "@/types/Openf1API/drivers";
 import { RaceResult } from "@/types/Openf1API/result";
-import { Position } from "@/types/Openf1API/positions";
+import { LapChart } from "@/types/Openf1API/positions";
 import {
   Chart as ChartJS,
   CategoryScale,
@@ -25,7 +25,7 @@ ChartJS.register(
 );
 
 interface Props {
-  data?: Position[] | null;
+  data?: LapChart | null;
   drivers?: Driver[];
   result?: RaceResult[];
   height?: number;
@@ -46,72 +46,77 @@ export default function LapRankingChart({
   }, [drivers]);
 
   const chartData = useMemo(() => {
-    if (!data || data.length === 0) return { labels: [], datasets: [] };
+    if (!data || data.laps.length === 0) return { labels: [], datasets: [] };
 
-    // 取得所有車手編號
-    const driverSet = new Set<number>();
-    data.forEach((lap) => lap.rank?.forEach((d) => driverSet.add(d)));
-    const driversSorted = Array.from(driverSet).sort((a, b) => a - b);
+    const gridPosition = new Map(
+      data.grid.map((g) => [g.driver_number, g.position]),
+    );
 
-    const datasets = driversSorted.map((drv) => {
-      // 處理完整的 lap ranking 資料
-      const values = data.map((lap) => {
-        if (!lap.rank) return null;
-        const idx = lap.rank.indexOf(drv);
-        return idx >= 0 ? idx + 1 : null;
-      });
+    const datasets = [...data.drivers]
+      .sort((a, b) => a - b)
+      .map((drv) => {
+        const cells = data.laps.map((lap) =>
+          lap.cells.find((c) => c.driver_number === drv),
+        );
 
-      // 找 result 資料
-      const res = result.find((r) => r.driver_number === drv);
-      const isDNF = res?.dnf ?? false;
+        // 第一格為發車位置，其後為每圈結束時的名次
+        const values: (number | null)[] = [
+          gridPosition.get(drv) ?? null,
+          ...cells.map((c) => c?.position ?? null),
+        ];
 
-      // 如果有 result 資料且與最後一圈不同，則替換最後一個值
-      if (res && values.length > 0) {
-        values[values.length - 1] = res.position ?? values[values.length - 1];
-      }
+        // 如果有 result 資料且與最後一圈不同，則替換最後一個值
+        const res = result.find((r) => r.driver_number === drv);
+        if (res && values.length > 0) {
+          values[values.length - 1] = res.position ?? values[values.length - 1];
+        }
 
-      // DNF flags (用於畫虛線) - 檢查每一圈是否DNF
-      const dnfFlags = data.map((lap) => {
-        if (!lap.rank || !lap.dnf) return false;
-        const idx = lap.rank.indexOf(drv);
-        return idx >= 0 ? lap.dnf[idx] : false;
-      });
+        // 退賽或被套圈後未跑的圈畫虛線
+        const dashed = [
+          false,
+          ...cells.map(
+            (c) => c?.status === "retired" || c?.status === "finished",
+          ),
+        ];
+        if (res?.dnf && dashed.length > 0) {
+          dashed[dashed.length - 1] = true;
+        }
 
-      // 如果車手最終DNF，將最後一格也標記為DNF
-      if (isDNF && dnfFlags.length > 0) {
-        dnfFlags[dnfFlags.length - 1] = true;
-      }
+        // 進站圈以較大的點標示
+        const pointRadius = [
+          3,
+          ...cells.map((c) => (c?.status === "pitted" ? 5 : 3)),
+        ];
 
-      const color = driverMap[drv]?.team_colour
-        ? `#${driverMap[drv].team_colour}`
-        : "#888";
+        const color = driverMap[drv]?.team_colour
+          ? `#${driverMap[drv].team_colour}`
+          : "#888";
 
-      return {
-        label: driverMap[drv]?.full_name ?? `#${drv}`,
-        data: values,
-        borderColor: color,
-        backgroundColor: color,
-        tension: 0,
-        spanGaps: true, // 改為 true 讓線段連續
-        fill: false,
-        segment: {
-          borderDash: (ctx: any) => {
-            const i = ctx.p0DataIndex;
-            // 如果當前點或下一個點是 DNF，就畫虛線
-            if (
-              dnfFlags[i] ||
-              (dnfFlags[i + 1] !== undefined && dnfFlags[i + 1])
-            ) {
-              return [5, 5]; // 虛線樣式
-            }
-            return []; // 實線
+        return {
+          label: driverMap[drv]?.full_name ?? `#${drv}`,
+          data: values,
+          borderColor: color,
+          backgroundColor: color,
+          pointRadius,
+          tension: 0,
+          spanGaps: true,
+          fill: false,
+          segment: {
+            borderDash: (ctx: any) => {
+              const i = ctx.p0DataIndex;
+              if (
+                dashed[i] ||
+                (dashed[i + 1] !== undefined && dashed[i + 1])
+              ) {
+                return [5, 5]; // 虛線樣式
+              }
+              return []; // 實線
+            },
           },
-        },
-      };
-    });
+        };
+      });
 
-    // 標籤：使用原本的 lap 編號
-    const labels = data.map((lap) => lap.lap);
+    const labels = ["Grid", ...data.laps.map((lap) => lap.lap)];
 
     return {
       labels,
@@ -119,42 +124,27 @@ export default function LapRankingChart({
     };
   }, [data, driverMap, result]);
 
+  // 🔹 左側軸 = 發車位置
   const leftYAxisLabels = useMemo(() => {
-    if (!data || data.length === 0) return {};
-
-    const firstLap = data[0];
-    if (!firstLap || !firstLap.rank) return {};
+    if (!data || data.grid.length === 0) return {};
 
     const labels: Record<number, { label: string; color: string }> = {};
-    const maxRank = firstLap.rank.length;
-
-    for (let position = 1; position <= maxRank; position++) {
-      const driverNumber = firstLap.rank[position - 1];
-      labels[position] = {
-        label: driverMap[driverNumber]?.name_acronym ?? `#${driverNumber}`,
-        color: driverMap[driverNumber]?.team_colour
-          ? `#${driverMap[driverNumber].team_colour}`
+    data.grid.forEach((g) => {
+      labels[g.position] = {
+        label:
+          driverMap[g.driver_number]?.name_acronym ?? `#${g.driver_number}`,
+        color: driverMap[g.driver_number]?.team_colour
+          ? `#${driverMap[g.driver_number].team_colour}`
           : "#888",
       };
-    }
+    });
 
     return labels;
   }, [data, driverMap]);
 
   const yAxisRange = useMemo(() => {
-    if (!data || data.length === 0) return { min: 1, max: 20 };
-
-    const allRanks = new Set<number>();
-    data.forEach((lap) => {
-      lap.rank?.forEach((_, index) => {
-        allRanks.add(index + 1);
-      });
-    });
-
-    const min = Math.min(...Array.from(allRanks));
-    const max = Math.max(...Array.from(allRanks));
-
-    return { min, max };
+    if (!data || data.drivers.length === 0) return { min: 1, max: 20 };
+    return { min: 1, max: data.drivers.length };
   }, [data]);
 
   // 🔹 右側軸 = result (包含DNF車手)
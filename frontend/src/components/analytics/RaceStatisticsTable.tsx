@@ -1,7 +1,7 @@
 import { useMemo } from "react";
 import { Driver } from "@/types/Openf1API/drivers";
 import { RaceResult } from "@/types/Openf1API/result";
-import { Positions } from "@/types/Openf1API/positions";
+import { LapChart } from "@/types/Openf1API/positions";
 import { TeamStats } from "@/types/analytics";
 import { Trophy, Clock, Award, TrendingUp, AlertCircle } from "lucide-react";
 
@@ -10,7 +10,7 @@ const POINTS: number[] = [25, 18, 15, 12, 10, 8, 6, 4, 2, 1];
 interface Props {
   drivers: Driver[];
   results: RaceResult[];
-  lapRankings: Positions | null;
+  lapRankings: LapChart | null;
   grandPrixName: string;
 }
 
@@ -119,7 +119,7 @@ export default function RaceStatisticsTable({
     const dsqCount = results.filter((r) => r.dsq).length;
     const totalDrivers = results.length;
     const validDrivers = validResults.length;
-    const totalLaps = lapRankings?.length || 0;
+    const totalLaps = lapRankings?.total_laps || 0;
 
     return {
       totalLaps,
@@ -9,7 +9,7 @@ interface PositionBoxPlotProps {
 export const PositionBoxPlot: React.FC<PositionBoxPlotProps> = ({ data }) => {
   const boxPlotData = data.driver_standings
     .map((driver) => {
-      const validPos = driver.positions.filter((p) => p > 0); // exclude DNF (-1) or 0
+      const validPos = driver.race_positions.filter((p) => p > 0); // exclude DNF (-1) or 0
       if (validPos.length === 0) return null;
 
       const sorted = [...validPos].sort((a, b) => a - b);
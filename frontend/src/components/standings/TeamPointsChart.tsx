@@ -9,17 +9,26 @@ import {
   ResponsiveContainer,
 } from "recharts";
 import { Trophy } from "lucide-react";
-import { SeasonStanding } from "@/types/Openf1API/standings";
+import { DriverStanding, SeasonStanding } from "@/types/Openf1API/standings";
 
 interface TeamPointsChartProps {
   data: SeasonStanding;
 }
 
 export const TeamPointsChart: React.FC<TeamPointsChartProps> = ({ data }) => {
+  // 車隊積分依每場代表的車隊計算，賽季中轉隊的車手積分分屬前後兩隊
+  const teamOf = (driver: DriverStanding, idx: number) =>
+    driver.round_teams?.[idx] || driver.team_name;
+
   const teamColours: Record<string, string> = {};
   data.driver_standings.forEach((driver) => {
     teamColours[driver.team_name] = driver.team_colour;
   });
+  data.driver_standings.forEach((driver) => {
+    driver.round_teams?.forEach((team) => {
+      if (team && !teamColours[team]) teamColours[team] = "888888";
+    });
+  });
   const teamNames = Object.keys(teamColours);
 
   const merged: Record<string, Record<string, number>> = {};
@@ -30,7 +39,7 @@ export const TeamPointsChart: React.FC<TeamPointsChartProps> = ({ data }) => {
 
     teamNames.forEach((team) => {
       const teamPoints = data.driver_standings
-        .filter((d) => d.team_name === team)
+        .filter((d) => teamOf(d, idx) === team)
         .reduce((sum, driver) => sum + (driver.round_points[idx] || 0), 0);
 
       if (!merged[prefix][team]) merged[prefix][team] = 0;
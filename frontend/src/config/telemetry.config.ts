@@ -4,10 +4,11 @@
 export const filterValidLaps = (laps: any[]): any[] => {
   return laps.filter((lap) => {
     const hasValidDuration = lap.lap_duration !== null;
+    const isNotDeleted = !lap.deleted;
     const isNotFirstLap = lap.lap_number !== 1;
     const isNotLastLap = lap.lap_number !== laps.length;
 
-    return hasValidDuration && isNotFirstLap && isNotLastLap;
+    return hasValidDuration && isNotDeleted && isNotFirstLap && isNotLastLap;
   });
 };
 
@@ -1,5 +1,5 @@
 import { useState, useEffect } from "react";
-import { Position } from "@/types/Openf1API/positions";
+import { LapChart } from "@/types/Openf1API/positions";
 import { OpenF1Service } from "@/services/Openf1API/positions";
 import { withRetry } from "@/utils/retry";
 import { COMMON_CONFIG } from "@/config/config";
@@ -8,18 +8,18 @@ interface UsePositionsBySessionProps {
   sessionKey: number | null;
 }
 
-const cache: { [sessionKey: number]: Position[] } = {};
+const cache: { [sessionKey: number]: LapChart } = {};
 
 export const usePositionsBySession = ({
   sessionKey,
 }: UsePositionsBySessionProps) => {
-  const [positions, setPositions] = useState<Position[]>([]);
+  const [positions, setPositions] = useState<LapChart | null>(null);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<Error | null>(null);
 
   useEffect(() => {
     if (!sessionKey) {
-      setPositions([]);
+      setPositions(null);
       return;
     }
 
@@ -13,7 +13,7 @@ import type {
   Driver,
   Stint,
   RaceResult,
-  Positions,
+  LapChart,
   RaceControl,
 } from "@/types/Openf1API";
 import { ANALYTICS_CONFIG } from "@/config/config";
@@ -30,7 +30,7 @@ interface AnalyticsLayoutProps {
   setSelectedSession: (session: Session | null) => void;
 
   // Analytics data
-  lapRankings: Positions | null;
+  lapRankings: LapChart | null;
   drivers: Driver[];
   stints: Record<number, Stint[]> | null;
   results: RaceResult[];
@@ -1,10 +1,10 @@
 import { baseApiClient } from "@/services/baseApiClient";
-import { Position } from "@/types/Openf1API/positions";
+import { LapChart } from "@/types/Openf1API/positions";
 
 export class OpenF1Service extends baseApiClient {
   // ========== Positions ==========
-  static async getPositionsbySession(sessionKey: number): Promise<Position[]> {
-    return this.fetchData<Position[]>(
+  static async getPositionsbySession(sessionKey: number): Promise<LapChart> {
+    return this.fetchData<LapChart>(
       this.getUrl(`/openf1/position/${sessionKey}`),
     );
   }
@@ -1,5 +1,9 @@
 import { baseApiClient } from "@/services/baseApiClient";
 import { RaceControl } from "@/types/Openf1API/raceControl";
+import {
+  RaceControlData,
+  RaceControlEventType,
+} from "@/types/LiveTiming/liveTiming";
 
 export class OpenF1Service extends baseApiClient {
   // ========== Race Control ==========
@@ -10,4 +14,14 @@ export class OpenF1Service extends baseApiClient {
       this.getUrl(`/openf1/race_control/${sessionKey}`),
     );
   }
+
+  static async getRaceControlEvents(
+    sessionKey: number,
+    types: RaceControlEventType[] = [],
+  ): Promise<RaceControlData[]> {
+    const query = types.length ? `?type=${types.join(",")}` : "";
+    return this.fetchData<RaceControlData[]>(
+      this.getUrl(`/openf1/race_control/${sessionKey}/events${query}`),
+    );
+  }
 }
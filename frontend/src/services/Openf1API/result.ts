@@ -1,5 +1,5 @@
 import { baseApiClient } from "@/services/baseApiClient";
-import { RaceResult } from "@/types/Openf1API/result";
+import { QualifyingAnalysis, RaceResult } from "@/types/Openf1API/result";
 
 export class OpenF1Service extends baseApiClient {
   // ========== Results ==========
@@ -8,4 +8,13 @@ export class OpenF1Service extends baseApiClient {
       this.getUrl(`/openf1/result/${sessionKey}`),
     );
   }
+
+  // ========== Qualifying ==========
+  static async getQualifyingbySession(
+    sessionKey: number,
+  ): Promise<QualifyingAnalysis> {
+    return this.fetchData<QualifyingAnalysis>(
+      this.getUrl(`/openf1/qualifying/${sessionKey}`),
+    );
+  }
 }
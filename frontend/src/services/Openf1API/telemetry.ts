@@ -1,5 +1,9 @@
 import { baseApiClient } from "@/services/baseApiClient";
-import { TelemetryLap } from "@/types/Openf1API/telemetry";
+import {
+  LapTelemetry,
+  TelemetryLap,
+  TelemetryOptions,
+} from "@/types/Openf1API/telemetry";
 
 export class OpenF1Service extends baseApiClient {
   // ========== Telemetry ==========
@@ -14,4 +18,24 @@ export class OpenF1Service extends baseApiClient {
       ),
     );
   }
+
+  static async getTelemetrySeries(
+    sessionKey: number,
+    driverNumber: number,
+    lapNumber: number,
+    options: TelemetryOptions,
+  ): Promise<LapTelemetry> {
+    const params = new URLSearchParams();
+    if (options.points) params.set("points", String(options.points));
+    if (options.hz) params.set("hz", String(options.hz));
+    if (options.channels?.length)
+      params.set("channels", options.channels.join(","));
+    params.set("time", options.time ?? "date");
+
+    return this.fetchData<LapTelemetry>(
+      this.getUrl(
+        `/openf1/telemetry/${sessionKey}/${driverNumber}/${lapNumber}?${params.toString()}`,
+      ),
+    );
+  }
 }
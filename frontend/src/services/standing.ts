@@ -25,7 +25,7 @@ export class StandingsService extends baseApiClient {
           team_name: driver.team_name,
           team_colour: driver.team_colour,
           total_points: 0,
-          cumulative_points: new Array(seasonStanding.total_rounds).fill(0),
+          cumulative_points: new Array(seasonStanding.scored_rounds).fill(0),
           drivers: [],
         });
       }
@@ -55,7 +55,7 @@ export class StandingsService extends baseApiClient {
     return seasonStanding.driver_standings
       .map((driver) => {
         // 過濾掉無效位置(-1, 0)
-        const validPositions = driver.positions.filter((pos) => pos > 0);
+        const validPositions = driver.race_positions.filter((pos) => pos > 0);
         const sorted = [...validPositions].sort((a, b) => a - b);
 
         const calculateMedian = (arr: number[]): number => {
@@ -24,6 +24,45 @@ export interface WeatherData {
   windSpeed: number;
 }
 
+export type RaceControlEventType =
+  | "flag"
+  | "blue_flag"
+  | "chequered_flag"
+  | "safety_car_deployed"
+  | "safety_car_ending"
+  | "vsc_deployed"
+  | "vsc_ending"
+  | "drs_enabled"
+  | "drs_disabled"
+  | "track_limits"
+  | "lap_deleted"
+  | "lap_reinstated"
+  | "incident_noted"
+  | "investigation"
+  | "no_further_action"
+  | "penalty"
+  | "penalty_served"
+  | "session_start"
+  | "session_stopped"
+  | "session_resume"
+  | "session_resume_time"
+  | "other";
+
+export interface RacePenalty {
+  type:
+    | "time"
+    | "drive_through"
+    | "stop_go"
+    | "grid"
+    | "reprimand"
+    | "disqualified"
+    | "other";
+  seconds?: number;
+  grid_places?: number;
+  reason?: string;
+}
+
+// 後端 /openf1/race_control/:session_key/events 解析後的欄位皆為選填
 export interface RaceControlData {
   category: string;
   flag?: string;
@@ -32,6 +71,13 @@ export interface RaceControlData {
   scope?: string;
   sector?: number;
   utc: string;
+  type?: RaceControlEventType;
+  driver_number?: number;
+  drivers?: number[];
+  turn?: number;
+  deleted_time?: string;
+  reason?: string;
+  penalty?: RacePenalty;
 }
 
 export interface DriversData {
@@ -15,6 +15,9 @@ export type Lap = {
   segments_sector_2: number[];
   segments_sector_3: number[];
   st_speed: number;
+  neutralised?: "SC" | "VSC" | "RED"; // SC / VSC / 紅旗期間的圈
+  deleted?: boolean; // race control 刪除的圈 (超出賽道界線等)
+  deleted_reason?: string;
 };
 
 export type Laps = Lap[];
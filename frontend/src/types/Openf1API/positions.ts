@@ -1,7 +1,34 @@
-export type Position = {
+// lapped: 仍在場上但落後領先者；finished: 被套圈的完賽者在領先者衝線後沒有跑的圈
+export type LapChartStatus =
+  | "running"
+  | "pitted"
+  | "lapped"
+  | "finished"
+  | "retired";
+
+export type LapChartCell = {
+  driver_number: number;
+  position: number;
+  status: LapChartStatus;
+  laps_down?: number; // 落後領先者的圈數
+};
+
+// 單圈結束時的名次，cells 依名次排序
+export type LapChartLap = {
   lap: number;
-  dnf: boolean[]; // 每位車手是否 DNF
-  rank: number[]; // 每位車手的排名
+  cells: LapChartCell[];
 };
 
-export type Positions = Position[];
+export type GridSlot = {
+  position: number;
+  driver_number: number;
+  lap_duration?: number; // 排位成績
+};
+
+export type LapChart = {
+  session_key: number;
+  total_laps: number;
+  drivers: number[];
+  grid: GridSlot[];
+  laps: LapChartLap[];
+};
@@ -13,3 +13,53 @@ export type RaceResult = {
 };
 
 export type RaceResults = RaceResult[];
+
+// ========== 排位賽淘汰分析 ==========
+
+export type QualifyingRun = {
+  run: number;
+  timed_laps: number;
+  best_lap: number | null;
+  improvement: number | null; // 與之前最佳圈的差，負數為變快
+};
+
+export type DriverQualifyingSegment = {
+  segment: string; // Q1 / Q2 / Q3
+  position: number; // 該節名次
+  best_lap: number | null;
+  gap_to_fastest: number | null;
+  gap_to_cutoff: number | null; // 正數為慢於晉級門檻
+  runs: QualifyingRun[];
+};
+
+export type QualifyingDriver = {
+  driver_number: number;
+  name_acronym: string;
+  team_name: string;
+  team_colour: string;
+  position: number;
+  eliminated_in?: string;
+  knocked_out_by: number | null;
+  outside_107: boolean;
+  segments: DriverQualifyingSegment[];
+};
+
+export type QualifyingSegment = {
+  name: string;
+  start: string;
+  end: string | null;
+  participants: number;
+  advance: number; // 晉級人數，最後一節為 0
+  fastest_lap: number | null;
+  fastest_driver: number | null;
+  cutoff_time: number | null;
+  cutoff_driver: number | null;
+  threshold_107?: number;
+  eliminated?: number[];
+};
+
+export type QualifyingAnalysis = {
+  session_key: number;
+  segments: QualifyingSegment[];
+  drivers: QualifyingDriver[];
+};
@@ -3,6 +3,22 @@ export type SeasonStanding = {
   total_rounds: number;
   locations: string[]; // 每場比賽標識
   driver_standings: DriverStanding[];
+  rounds: RoundStatus[]; // 每場比賽資料狀態
+  scored_rounds: number; // 車手各場資料涵蓋的場數，第一個缺漏場次之後不提供積分與排名
+  complete: boolean; // 已開賽的場次是否都有結果
+  missing_rounds?: number[]; // 結果缺漏的場次
+};
+
+export type RoundDataStatus = "complete" | "missing" | "pending";
+
+export type RoundStatus = {
+  round: number;
+  session_key: number;
+  session_name: string; // Race / Sprint
+  date_start: string;
+  location: string;
+  status: RoundDataStatus;
+  retrying: boolean;
 };
 
 export type DriverStanding = {
@@ -13,6 +29,8 @@ export type DriverStanding = {
   team_colour: string; // Hex 色碼
   headshot_url: string;
   round_points: number[]; // 每場比賽獲得的積分
+  round_teams?: string[]; // 每場比賽代表的車隊 (未出賽為空字串)
   cumulative_points: number[]; // 累積積分
-  positions: number[]; // 每場比賽排名 (-1 代表未參加或 DNF)
+  race_positions: number[]; // 每場比賽完賽名次 (-1 代表未參加或 DNF)
+  championship_positions: number[]; // 每場比賽後的積分榜排名
 };
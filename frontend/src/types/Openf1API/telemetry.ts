@@ -17,3 +17,36 @@ export type TelemetryData = {
 };
 
 export type TelemetryLaps = TelemetryLap[];
+
+export type TelemetryChannel =
+  | "speed"
+  | "rpm"
+  | "n_gear"
+  | "throttle"
+  | "brake"
+  | "drs";
+
+export type TelemetryOptions = {
+  points?: number; // LTTB 降採樣點數
+  hz?: number; // 固定頻率重新取樣
+  channels?: TelemetryChannel[];
+  time?: "offset" | "date";
+};
+
+// 欄位式遙測，未選取的通道不會出現
+export type LapTelemetry = {
+  lap_number: number;
+  driver_number: number;
+  start_time: string;
+  end_time: string;
+  samples: number;
+  raw_samples: number;
+  date?: string[];
+  time?: number[]; // 距圈起點秒數
+  speed?: number[];
+  rpm?: number[];
+  n_gear?: number[];
+  throttle?: number[];
+  brake?: number[];
+  drs?: number[];
+};